@@ -0,0 +1,63 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+const (
+	ActorHeader     = "X-Actor"
+	RequestIDHeader = "X-Request-ID"
+
+	// AnonymousActor is recorded for changes sent without an X-Actor header
+	AnonymousActor = "anonymous"
+
+	// maxHeaderValue is the number of characters the audit log keeps of the
+	// actor and the request id
+	maxHeaderValue = 128
+)
+
+// WithRequestID makes sure every request carries an X-Request-ID, a missing,
+// oversized or non UTF-8 one is replaced by a random id. The id is echoed in the
+// response so clients can look up what their request changed.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+		if id == "" || len(id) > maxHeaderValue || !utf8.ValidString(id) {
+			id = newRequestID()
+			r.Header.Set(RequestIDHeader, id)
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ActorFromRequest tells who makes a change from the X-Actor header. Invalid
+// UTF-8 in the name is replaced and a long name is cut to maxHeaderValue
+// characters.
+func ActorFromRequest(r *http.Request) models.Actor {
+	name := strings.ToValidUTF8(strings.TrimSpace(r.Header.Get(ActorHeader)), "\uFFFD")
+	if name == "" {
+		name = AnonymousActor
+	}
+	if runes := []rune(name); len(runes) > maxHeaderValue {
+		name = string(runes[:maxHeaderValue])
+	}
+
+	return models.Actor{
+		Name:      name,
+		RequestID: strings.TrimSpace(r.Header.Get(RequestIDHeader)),
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
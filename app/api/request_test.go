@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequestID(t *testing.T) {
+	var seen string
+	handler := WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = ActorFromRequest(r).RequestID
+	}))
+
+	t.Run("keeps the id sent by the client", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, "req-42")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, "req-42", seen)
+		assert.Equal(t, "req-42", recorder.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("generates a missing id", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+		assert.Len(t, seen, 32)
+		assert.Equal(t, seen, recorder.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("replaces an id that is not UTF-8", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, "req-\xff")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		assert.Len(t, seen, 32)
+		assert.Equal(t, seen, recorder.Header().Get(RequestIDHeader))
+	})
+}
+
+func TestActorFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, AnonymousActor, ActorFromRequest(req).Name)
+
+	req.Header.Set(ActorHeader, " jane@example.com ")
+	assert.Equal(t, "jane@example.com", ActorFromRequest(req).Name)
+
+	// Long names are cut on character boundaries
+	req.Header.Set(ActorHeader, "a"+strings.Repeat("é", maxHeaderValue))
+	name := ActorFromRequest(req).Name
+	assert.True(t, utf8.ValidString(name))
+	assert.Equal(t, maxHeaderValue, utf8.RuneCountInString(name))
+
+	req.Header.Set(ActorHeader, "jane\xff")
+	assert.Equal(t, "jane\uFFFD", ActorFromRequest(req).Name)
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type AuditHandler struct {
+	service *AuditService
+}
+
+func NewAuditHandler(service *AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// HandleList serves GET /audit?entity=product&code=PROD001
+func (h *AuditHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	offset := utils.ParseIntParam(query.Get("offset"), 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	limit := utils.ParseIntParam(query.Get("limit"), 50)
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	filter := models.AuditFilter{
+		Entity: query.Get("entity"),
+		Code:   query.Get("code"),
+	}
+
+	response, err := h.service.ListEntries(offset, limit, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEntity), errors.Is(err, ErrCodeRequired):
+			api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
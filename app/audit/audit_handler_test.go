@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditRepo struct {
+	listFn func(int, int, models.AuditFilter) ([]models.AuditEntry, int64, error)
+}
+
+func (m *mockAuditRepo) GetAuditEntries(offset, limit int, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+	if m.listFn != nil {
+		return m.listFn(offset, limit, filter)
+	}
+	return nil, 0, errors.New("not implemented")
+}
+
+func TestHandleList(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockAuditRepo{
+		listFn: func(offset, limit int, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+			assert.Equal(t, 0, offset)
+			assert.Equal(t, 50, limit)
+			assert.Equal(t, models.AuditFilter{Entity: "product", Code: "PROD001"}, filter)
+			return []models.AuditEntry{
+				{
+					ID:        2,
+					Actor:     "jane@example.com",
+					RequestID: "req-42",
+					Action:    models.AuditActionUpdate,
+					Entity:    models.AuditEntityProduct,
+					Code:      "PROD001",
+					Changes:   `{"price":{"before":"10.99","after":"12.50"}}`,
+					CreatedAt: createdAt,
+				},
+			}, 1, nil
+		},
+	}
+
+	handler := NewAuditHandler(NewAuditService(repo))
+	req := httptest.NewRequest("GET", "/audit?entity=product&code=prod001", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"entries": [{
+			"id": 2,
+			"actor": "jane@example.com",
+			"request_id": "req-42",
+			"action": "update",
+			"entity": "product",
+			"code": "PROD001",
+			"created_at": "2026-01-02T10:00:00Z",
+			"changes": {"price": {"before": "10.99", "after": "12.50"}}
+		}],
+		"total": 1,
+		"offset": 0,
+		"limit": 50
+	}`, w.Body.String())
+}
+
+func TestHandleList_VariantCodeKeepsCase(t *testing.T) {
+	repo := &mockAuditRepo{
+		listFn: func(offset, limit int, filter models.AuditFilter) ([]models.AuditEntry, int64, error) {
+			assert.Equal(t, "sku001-r", filter.Code)
+			assert.Equal(t, 200, limit)
+			return nil, 0, nil
+		},
+	}
+
+	handler := NewAuditHandler(NewAuditService(repo))
+	req := httptest.NewRequest("GET", "/audit?entity=variant&code=sku001-r&limit=1000", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp EntriesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Entries)
+}
+
+func TestHandleList_Errors(t *testing.T) {
+	repo := &mockAuditRepo{
+		listFn: func(int, int, models.AuditFilter) ([]models.AuditEntry, int64, error) {
+			return nil, 0, errors.New("database connection failed")
+		},
+	}
+	handler := NewAuditHandler(NewAuditService(repo))
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"unknown entity", "/audit?entity=order", http.StatusBadRequest},
+		{"code without entity", "/audit?code=PROD001", http.StatusBadRequest},
+		{"database error", "/audit?entity=category", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleList(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type AuditService struct {
+	repo EntriesReader
+}
+
+func NewAuditService(repo EntriesReader) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// ListEntries returns the audit entries of an entity newest first. Without
+// an entity it lists all entries, a code alone is ambiguous since a sku may
+// look like a product code.
+func (s *AuditService) ListEntries(offset, limit int, filter models.AuditFilter) (*EntriesResponse, error) {
+	filter.Entity = strings.ToLower(strings.TrimSpace(filter.Entity))
+	filter.Code = strings.TrimSpace(filter.Code)
+
+	switch filter.Entity {
+	case "", models.AuditEntityProduct, models.AuditEntityVariant, models.AuditEntityCategory:
+	default:
+		return nil, ErrInvalidEntity
+	}
+	if filter.Entity == "" && filter.Code != "" {
+		return nil, ErrCodeRequired
+	}
+
+	// Product and category codes are stored upper case, skus as they were sent
+	if filter.Entity != models.AuditEntityVariant {
+		filter.Code = strings.ToUpper(filter.Code)
+	}
+
+	entries, total, err := s.repo.GetAuditEntries(offset, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]Entry, len(entries))
+	for i, e := range entries {
+		dtos[i] = mapEntryToDTO(e)
+	}
+
+	return &EntriesResponse{
+		Entries: dtos,
+		Total:   total,
+		Offset:  offset,
+		Limit:   limit,
+	}, nil
+}
+
+func mapEntryToDTO(e models.AuditEntry) Entry {
+	changes := json.RawMessage(e.Changes)
+	if !json.Valid(changes) {
+		changes = json.RawMessage("{}")
+	}
+
+	return Entry{
+		ID:        e.ID,
+		Actor:     e.Actor,
+		RequestID: e.RequestID,
+		Action:    e.Action,
+		Entity:    e.Entity,
+		Code:      e.Code,
+		CreatedAt: e.CreatedAt,
+		Changes:   changes,
+	}
+}
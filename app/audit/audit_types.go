@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+var (
+	ErrInvalidEntity = errors.New("entity must be product, variant or category")
+	ErrCodeRequired  = errors.New("code needs an entity")
+)
+
+type EntriesReader interface {
+	GetAuditEntries(offset, limit int, filter models.AuditFilter) ([]models.AuditEntry, int64, error)
+}
+
+type Entry struct {
+	ID        uint      `json:"id"`
+	Actor     string    `json:"actor"`
+	RequestID string    `json:"request_id"`
+	Action    string    `json:"action"`
+	Entity    string    `json:"entity"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+	// Changes maps every changed field to its before and after value
+	Changes json.RawMessage `json:"changes"`
+}
+
+type EntriesResponse struct {
+	Entries []Entry `json:"entries"`
+	Total   int64   `json:"total"`
+	Offset  int     `json:"offset"`
+	Limit   int     `json:"limit"`
+}
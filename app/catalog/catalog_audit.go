@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// The snapshots below are what the audit log compares before and after a
+// write. They hold the stored values, not the localized or converted ones
+// shown in responses.
+
+func productSnapshot(product *models.Product) map[string]any {
+	var category any
+	if product.Category != nil {
+		category = product.Category.Code
+	}
+
+	return map[string]any{
+		"code":          product.Code,
+		"price":         product.Price.StringFixed(2),
+		"category":      category,
+		"brand":         product.Brand,
+		"status":        product.Status,
+		"publish_from":  snapshotTime(product.PublishFrom),
+		"publish_until": snapshotTime(product.PublishUntil),
+		"tax_class":     product.TaxClass,
+	}
+}
+
+func variantSnapshot(code string, variant *models.Variant) map[string]any {
+	// A variant without its own price inherits the product price
+	var price any
+	if !variant.Price.IsZero() {
+		price = variant.Price.StringFixed(2)
+	}
+
+	attributes := make(map[string]string, len(variant.Attributes))
+	for _, a := range variant.Attributes {
+		if a.Option != nil && a.OptionValue != nil {
+			attributes[a.Option.Name] = a.OptionValue.Value
+		}
+	}
+
+	return map[string]any{
+		"product":    code,
+		"sku":        variant.SKU,
+		"name":       variant.Name,
+		"price":      price,
+		"attributes": attributes,
+	}
+}
+
+func optionsSnapshot(options []models.ProductOption) map[string]any {
+	return map[string]any{"options": mapOptionsToDTO(options)}
+}
+
+// translationSnapshot keys the texts by locale, so entries of different
+// locales can be told apart
+func translationSnapshot(translation *models.ProductTranslation) map[string]any {
+	return map[string]any{
+		"title." + translation.Locale:       translation.Title,
+		"description." + translation.Locale: translation.Description,
+	}
+}
+
+func mediaSnapshot(media *models.ProductMedia) map[string]any {
+	var variant any
+	if media.Variant != nil {
+		variant = media.Variant.SKU
+	}
+
+	return map[string]any{
+		// A pointer, the id of new media is only known once it's inserted
+		"id":       &media.ID,
+		"url":      media.URL,
+		"alt_text": media.AltText,
+		"width":    media.Width,
+		"height":   media.Height,
+		"position": media.Position,
+		"variant":  variant,
+	}
+}
+
+// deletedSnapshot marks an entity as soft-deleted or not, for restores
+func deletedSnapshot(deleted bool) map[string]any {
+	return map[string]any{"deleted": deleted}
+}
+
+func snapshotTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// productTranslation returns the stored texts of a product in exactly that
+// locale, or nil
+func productTranslation(product *models.Product, locale string) *models.ProductTranslation {
+	for i := range product.Translations {
+		if product.Translations[i].Locale == locale {
+			return &product.Translations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// cursor is the decoded form of the opaque next_cursor token. It remembers
+// the sort it was created for, so it can't be replayed against another one.
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// encodeCursor captures the sort values of the last product of a page
+func encodeCursor(sort []models.SortField, last models.Product, prices *pricing) string {
+	c := cursor{Sort: formatSort(sort)}
+
+	for _, value := range keysetValues(sort, last, prices) {
+		raw, _ := json.Marshal(value)
+		c.Values = append(c.Values, raw)
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// keysetValues returns the sort values of a product in the form of
+// ProductFilter.After, the price is its selling price in the response
+// currency like in the sort
+func keysetValues(sort []models.SortField, product models.Product, prices *pricing) []any {
+	values := make([]any, len(sort))
+	for i, f := range sort {
+		switch f.Field {
+		case "id":
+			values[i] = product.ID
+		case "code":
+			values[i] = product.Code
+		case "price":
+			values[i] = prices.selling(product)
+		case "relevance":
+			values[i] = product.SearchRank
+		}
+	}
+	return values
+}
+
+// decodeCursor returns the keyset values of a cursor created for the same sort
+func decodeCursor(token string, sort []models.SortField) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Sort != formatSort(sort) || len(c.Values) != len(sort) {
+		return nil, fmt.Errorf("%w: it was created for sort %q", ErrInvalidCursor, c.Sort)
+	}
+
+	values := make([]any, len(sort))
+	for i, f := range sort {
+		var err error
+		switch f.Field {
+		case "id":
+			var id uint
+			err = json.Unmarshal(c.Values[i], &id)
+			values[i] = id
+		case "code":
+			var code string
+			err = json.Unmarshal(c.Values[i], &code)
+			values[i] = code
+		case "price":
+			var price decimal.Decimal
+			err = json.Unmarshal(c.Values[i], &price)
+			values[i] = price
+		case "relevance":
+			var rank float64
+			err = json.Unmarshal(c.Values[i], &rank)
+			values[i] = rank
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
@@ -1,56 +1,182 @@
 package catalog
 
-import "github.com/mytheresa/go-hiring-challenge/models"
+import (
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
 
-func mapProductToDTO(p models.Product) Product {
+func mapProductToDTO(p models.Product, prices *pricing, locales []string) Product {
 	dto := Product{
-		Code:  p.Code,
-		Price: p.Price.InexactFloat64(),
+		Code:     p.Code,
+		Brand:    p.Brand,
+		Price:    prices.product(p),
+		Category: mapCategoryToDTO(p.Category, locales),
+		Status:   p.Status,
 	}
 
-	if p.Category != nil {
-		dto.Category = &Category{
-			Code: p.Category.Code,
-			Name: p.Category.Name,
-		}
+	dto.SalePrice, dto.PromotionIDs = prices.productSale(p, dto.Price)
+	dto.Tax = prices.taxed(p, selling(dto.Price, dto.SalePrice))
+
+	if t := findTranslation(p.Translations, locales); t != nil {
+		dto.Title = t.Title
+		dto.Locale = t.Locale
+	}
+
+	if len(p.Media) > 0 {
+		media := mapMediaToDTO(p.Media[0])
+		dto.PrimaryImage = &media
 	}
 
 	return dto
 }
 
+func mapProductToDetailDTO(p *models.Product, prices *pricing, locales []string) *ProductDetail {
+	detail := &ProductDetail{
+		Code:         p.Code,
+		Brand:        p.Brand,
+		Price:        prices.product(*p),
+		Category:     mapCategoryToDTO(p.Category, locales),
+		Status:       p.Status,
+		PublishFrom:  p.PublishFrom,
+		PublishUntil: p.PublishUntil,
+	}
 
-func mapProductToDetailDTO(p *models.Product) *ProductDetail {
-	productPrice := p.Price.InexactFloat64()
+	detail.SalePrice, detail.PromotionIDs = prices.productSale(*p, detail.Price)
+	detail.Tax = prices.taxed(*p, selling(detail.Price, detail.SalePrice))
 
-	detail := &ProductDetail{
-		Code:  p.Code,
-		Price: productPrice,
+	if p.TaxClass != nil {
+		detail.TaxClass = *p.TaxClass
 	}
 
-	if p.Category != nil {
-		detail.Category = &Category{
-			Code: p.Category.Code,
-			Name: p.Category.Name,
+	if t := findTranslation(p.Translations, locales); t != nil {
+		detail.Title = t.Title
+		detail.Description = t.Description
+		detail.Locale = t.Locale
+	}
+
+	if len(p.Options) > 0 {
+		detail.Options = mapOptionsToDTO(p.Options)
+	}
+
+	if len(p.Media) > 0 {
+		detail.Gallery = make([]Media, len(p.Media))
+		for i, m := range p.Media {
+			detail.Gallery[i] = mapMediaToDTO(m)
 		}
 	}
 
 	// Map variants with price inheritance logic
 	variants := make([]VariantDetail, len(p.Variants))
 	for i, v := range p.Variants {
-		variantPrice := productPrice
+		variants[i] = mapVariantToDetailDTO(v, *p, detail.Price, prices)
+	}
+	detail.Variants = variants
 
-		// Use variant specific price if set (non-zero)
-		if !v.Price.IsZero() {
-			variantPrice = v.Price.InexactFloat64()
+	return detail
+}
+
+func mapProductToExportDTO(p models.Product, prices *pricing, locales []string) ExportProduct {
+	dto := ExportProduct{Product: mapProductToDTO(p, prices, locales)}
+
+	dto.Variants = make([]VariantDetail, len(p.Variants))
+	for i, v := range p.Variants {
+		dto.Variants[i] = mapVariantToDetailDTO(v, p, dto.Price, prices)
+	}
+
+	return dto
+}
+
+// mapVariantToDetailDTO prices a variant of product, productPrice is the
+// already resolved price of the product
+func mapVariantToDetailDTO(v models.Variant, product models.Product, productPrice Price, prices *pricing) VariantDetail {
+	dto := VariantDetail{
+		Name:      v.Name,
+		SKU:       v.SKU,
+		Price:     prices.variant(v, productPrice),
+		Quantity:  v.Quantity,
+		Available: v.Quantity > 0,
+	}
+	dto.SalePrice, dto.PromotionIDs = prices.variantSale(product, v, dto.Price)
+	dto.Tax = prices.taxed(product, selling(dto.Price, dto.SalePrice))
+
+	for _, a := range v.Attributes {
+		if a.Option == nil || a.OptionValue == nil {
+			continue
+		}
+		if dto.Attributes == nil {
+			dto.Attributes = make(map[string]string, len(v.Attributes))
 		}
+		dto.Attributes[a.Option.Name] = a.OptionValue.Value
+	}
 
-		variants[i] = VariantDetail{
-			Name:  v.Name,
-			SKU:   v.SKU,
-			Price: variantPrice,
+	return dto
+}
+
+func mapOptionsToDTO(options []models.ProductOption) []OptionDetail {
+	dtos := make([]OptionDetail, len(options))
+	for i, o := range options {
+		dtos[i] = OptionDetail{
+			Name:   o.Name,
+			Values: make([]string, len(o.Values)),
+		}
+		for j, v := range o.Values {
+			dtos[i].Values[j] = v.Value
 		}
 	}
-	detail.Variants = variants
 
-	return detail
+	return dtos
+}
+
+func mapMediaToDTO(m models.ProductMedia) Media {
+	dto := Media{
+		ID:       m.ID,
+		URL:      m.URL,
+		AltText:  m.AltText,
+		Width:    m.Width,
+		Height:   m.Height,
+		Position: m.Position,
+	}
+
+	if m.Variant != nil {
+		dto.Variant = m.Variant.SKU
+	}
+
+	return dto
+}
+
+// mapCategoryToDTO names the category in the first locale of the chain it's
+// translated to, categories.name is the name in models.DefaultLocale
+func mapCategoryToDTO(c *models.Category, locales []string) *Category {
+	if c == nil {
+		return nil
+	}
+
+	available := make([]string, len(c.Translations))
+	for i, t := range c.Translations {
+		available[i] = t.Locale
+	}
+
+	name := c.Name
+	if i := utils.MatchLocale(locales, available); i >= 0 {
+		name = c.Translations[i].Name
+	}
+
+	return &Category{
+		Code: c.Code,
+		Name: name,
+	}
+}
+
+// findTranslation returns the translation in the first locale of the chain
+// the product is translated to
+func findTranslation(translations []models.ProductTranslation, locales []string) *models.ProductTranslation {
+	available := make([]string, len(translations))
+	for i, t := range translations {
+		available[i] = t.Locale
+	}
+
+	if i := utils.MatchLocale(locales, available); i >= 0 {
+		return &translations[i]
+	}
+	return nil
 }
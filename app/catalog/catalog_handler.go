@@ -1,11 +1,18 @@
 package catalog
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/mytheresa/go-hiring-challenge/app/api"
 	"github.com/mytheresa/go-hiring-challenge/app/utils"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
 )
 
 type CatalogHandler struct {
@@ -18,7 +25,18 @@ func NewCatalogHandler(service *CatalogService) *CatalogHandler {
 	}
 }
 
+// HandleGet lists the public catalog
 func (h *CatalogHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	h.listProducts(w, r, false)
+}
+
+// HandleAdminGet lists every product whatever its status and publishing
+// window, optionally narrowed down with status=draft|active|archived
+func (h *CatalogHandler) HandleAdminGet(w http.ResponseWriter, r *http.Request) {
+	h.listProducts(w, r, true)
+}
+
+func (h *CatalogHandler) listProducts(w http.ResponseWriter, r *http.Request, admin bool) {
 	offset := utils.ParseIntParam(r.URL.Query().Get("offset"), 0)
 	if offset < 0 {
 		offset = 0
@@ -32,37 +50,487 @@ func (h *CatalogHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
 		limit = 100
 	}
 
-	category := r.URL.Query().Get("category")
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	var priceLessThan *float64
-	if priceLessThanStr := r.URL.Query().Get("priceLessThan"); priceLessThanStr != "" {
-		var price float64
-		if _, err := fmt.Sscanf(priceLessThanStr, "%f", &price); err == nil && price > 0 {
-			priceLessThan = &price
+	if admin {
+		filter.IncludeUnpublished = true
+		if status := r.URL.Query().Get("status"); status != "" {
+			if !validStatus(status) {
+				api.ErrorResponse(w, http.StatusBadRequest, ErrProductStatusInvalid.Error())
+				return
+			}
+			filter.Status = strings.ToLower(status)
 		}
 	}
 
-	response, err := h.service.ListProducts(offset, limit, category, priceLessThan)
+	// A cursor replaces the offset, it continues after the last product it saw
+	if len(filter.After) > 0 {
+		offset = 0
+	}
+
+	facets, err := parseFacetOptions(r.URL.Query())
 	if err != nil {
-		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Country = parseCountry(r)
+
+	response, err := h.service.ListProducts(offset, limit, filter, facets, opts)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	api.SuccessResponse(w, response)
 }
 
+// HandleGetByCode only finds products of the public catalog
 func (h *CatalogHandler) HandleGetByCode(w http.ResponseWriter, r *http.Request) {
+	h.getProduct(w, r, false)
+}
+
+// HandleAdminGetByCode finds products whatever their status and publishing window
+func (h *CatalogHandler) HandleAdminGetByCode(w http.ResponseWriter, r *http.Request) {
+	h.getProduct(w, r, true)
+}
+
+func (h *CatalogHandler) getProduct(w http.ResponseWriter, r *http.Request, admin bool) {
+	code := r.PathValue("code")
+	if code == "" {
+		api.ErrorResponse(w, http.StatusBadRequest, "Product code is required")
+		return
+	}
+
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.IncludeUnpublished = admin
+	opts.Country = parseCountry(r)
+
+	if raw := r.URL.Query().Get("asOf"); raw != "" {
+		asOf, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			api.ErrorResponse(w, http.StatusBadRequest, ErrAsOfInvalid.Error())
+			return
+		}
+		opts.AsOf = &asOf
+	}
+
+	response, err := h.service.GetProductDetails(code, opts)
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			api.ErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CatalogHandler) HandlePriceHistory(w http.ResponseWriter, r *http.Request) {
+	h.priceHistory(w, r, false)
+}
+
+// HandleAdminPriceHistory also finds products outside the public catalog
+func (h *CatalogHandler) HandleAdminPriceHistory(w http.ResponseWriter, r *http.Request) {
+	h.priceHistory(w, r, true)
+}
+
+func (h *CatalogHandler) priceHistory(w http.ResponseWriter, r *http.Request, admin bool) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.IncludeUnpublished = admin
+
+	response, err := h.service.GetPriceHistory(r.PathValue("code"), opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CatalogHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreateProduct(api.ActorFromRequest(r), req, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, response)
+}
+
+func (h *CatalogHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	h.handleUpdate(w, r, h.service.UpdateProduct)
+}
+
+func (h *CatalogHandler) HandlePatch(w http.ResponseWriter, r *http.Request) {
+	h.handleUpdate(w, r, h.service.PatchProduct)
+}
+
+func (h *CatalogHandler) handleUpdate(w http.ResponseWriter, r *http.Request, update func(models.Actor, string, UpdateProductRequest, ViewOptions) (*ProductDetail, error)) {
 	code := r.PathValue("code")
 	if code == "" {
 		api.ErrorResponse(w, http.StatusBadRequest, "Product code is required")
 		return
 	}
 
-	response, err := h.service.GetProductDetails(code)
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := update(api.ActorFromRequest(r), code, req, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CatalogHandler) HandleReplaceOptions(w http.ResponseWriter, r *http.Request) {
+	var req ProductOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.ReplaceOptions(api.ActorFromRequest(r), r.PathValue("code"), req)
 	if err != nil {
-		api.ErrorResponse(w, http.StatusNotFound, "Product not found")
+		writeServiceError(w, err)
 		return
 	}
 
 	api.SuccessResponse(w, response)
 }
+
+func (h *CatalogHandler) HandleSaveTranslation(w http.ResponseWriter, r *http.Request) {
+	var req TranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.SaveTranslation(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("locale"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CatalogHandler) HandleDeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTranslation(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("locale")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CatalogHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.service.RestoreProduct(api.ActorFromRequest(r), r.PathValue("code"), opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CatalogHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	if code == "" {
+		api.ErrorResponse(w, http.StatusBadRequest, "Product code is required")
+		return
+	}
+
+	if err := h.service.DeleteProduct(api.ActorFromRequest(r), code); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseProductFilter reads the filter, sort and cursor parameters of GET /catalog
+func parseProductFilter(query url.Values) (models.ProductFilter, error) {
+	filter := models.ProductFilter{
+		Category:             query.Get("category"),
+		IncludeSubcategories: utils.ParseBoolParam(query.Get("includeSubcategories"), false),
+		Search:               strings.TrimSpace(query.Get("q")),
+		SkipTotal:            !utils.ParseBoolParam(query.Get("includeTotal"), true),
+		InStock:              utils.ParseBoolParam(query.Get("inStock"), false),
+	}
+
+	var err error
+	if filter.PriceLessThan, err = parsePriceParam(query, "priceLessThan"); err != nil {
+		return filter, err
+	}
+	if filter.PriceMin, err = parsePriceParam(query, "priceMin"); err != nil {
+		return filter, err
+	}
+	if filter.PriceMax, err = parsePriceParam(query, "priceMax"); err != nil {
+		return filter, err
+	}
+	if filter.PriceMin != nil && filter.PriceMax != nil && filter.PriceMin.GreaterThan(*filter.PriceMax) {
+		return filter, fmt.Errorf("%w: priceMin must not be greater than priceMax", ErrInvalidPriceFilter)
+	}
+
+	switch query.Get("priceMatch") {
+	case "", "product":
+	case "variant":
+		filter.MatchVariantPrices = true
+	default:
+		return filter, fmt.Errorf("%w: priceMatch must be product or variant", ErrInvalidPriceFilter)
+	}
+
+	if filter.Sort, err = parseSort(query.Get("sort"), filter.Search != ""); err != nil {
+		return filter, err
+	}
+
+	if filter.Attributes, err = parseAttributeFilter(query); err != nil {
+		return filter, err
+	}
+
+	if token := query.Get("cursor"); token != "" {
+		if filter.After, err = decodeCursor(token, filter.Sort); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// parseAttributeFilter reads attr.<option>=<value>[,<value>...] parameters,
+// e.g. attr.color=red,blue&attr.size=m
+func parseAttributeFilter(query url.Values) (map[string][]string, error) {
+	var attributes map[string][]string
+
+	for key, raw := range query {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, fmt.Errorf("%w: option name is missing", ErrInvalidAttributeFilter)
+		}
+
+		var values []string
+		for _, param := range raw {
+			for value := range strings.SplitSeq(param, ",") {
+				if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+					values = append(values, value)
+				}
+			}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: %s needs a value", ErrInvalidAttributeFilter, name)
+		}
+
+		if attributes == nil {
+			attributes = make(map[string][]string)
+		}
+		attributes[name] = append(attributes[name], values...)
+	}
+
+	return attributes, nil
+}
+
+// parseViewOptions reads priceFormat=number|exact, number is the default, the
+// currency and market the prices are returned in and the locale chain
+func parseViewOptions(r *http.Request) (ViewOptions, error) {
+	query := r.URL.Query()
+
+	locales, ok := utils.ParseLocales(r)
+	if !ok {
+		return ViewOptions{}, ErrInvalidLocale
+	}
+
+	opts := ViewOptions{
+		Locales:  locales,
+		Currency: strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+		Market:   strings.ToUpper(strings.TrimSpace(query.Get("market"))),
+	}
+
+	switch query.Get("priceFormat") {
+	case "", "number":
+	case "exact":
+		opts.ExactPrices = true
+	default:
+		return opts, ErrInvalidPriceFormat
+	}
+
+	return opts, nil
+}
+
+// parseCountry reads the country the taxes are shown for, e.g. country=DE
+func parseCountry(r *http.Request) string {
+	return strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("country")))
+}
+
+// parseFacetOptions reads facets=category,price and the optional
+// priceBucketSize, which defaults to buckets of 10
+func parseFacetOptions(query url.Values) (FacetOptions, error) {
+	options := FacetOptions{PriceBucketSize: decimal.NewFromInt(10)}
+
+	for name := range strings.SplitSeq(query.Get("facets"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "category":
+			options.Categories = true
+		case "price":
+			options.Price = true
+		default:
+			return options, fmt.Errorf("%w: %s", ErrInvalidFacet, name)
+		}
+	}
+
+	size, err := parsePriceParam(query, "priceBucketSize")
+	if err != nil {
+		return options, err
+	}
+	if size != nil {
+		if !size.IsPositive() {
+			return options, fmt.Errorf("%w: priceBucketSize must be positive", ErrInvalidFacet)
+		}
+		options.PriceBucketSize = *size
+	}
+
+	return options, nil
+}
+
+// parsePriceParam parses an optional non-negative decimal query parameter
+func parsePriceParam(query url.Values, name string) (*decimal.Decimal, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	price, err := decimal.NewFromString(raw)
+	if err != nil || price.IsNegative() {
+		return nil, fmt.Errorf("%w: %s must be a non-negative decimal", ErrInvalidPriceFilter, name)
+	}
+
+	return &price, nil
+}
+
+// writeServiceError maps the catalog service errors to HTTP status codes
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrProductCodeRequired),
+		errors.Is(err, ErrProductCodeTooLong),
+		errors.Is(err, ErrProductPriceInvalid),
+		errors.Is(err, ErrProductPricePrecision),
+		errors.Is(err, ErrCategoryNotFound),
+		errors.Is(err, ErrVariantNameRequired),
+		errors.Is(err, ErrVariantNameTooLong),
+		errors.Is(err, ErrVariantSKURequired),
+		errors.Is(err, ErrVariantSKUTooLong),
+		errors.Is(err, ErrVariantPriceInvalid),
+		errors.Is(err, ErrStockDeltaInvalid),
+		errors.Is(err, ErrWarehouseNotFound),
+		errors.Is(err, ErrOptionNameRequired),
+		errors.Is(err, ErrOptionValuesRequired),
+		errors.Is(err, ErrOptionTooLong),
+		errors.Is(err, ErrOptionDuplicate),
+		errors.Is(err, ErrInvalidAttribute),
+		errors.Is(err, ErrInvalidLocale),
+		errors.Is(err, ErrProductBrandTooLong),
+		errors.Is(err, ErrTitleRequired),
+		errors.Is(err, ErrTitleTooLong),
+		errors.Is(err, ErrProductStatusInvalid),
+		errors.Is(err, ErrPublishTimeInvalid),
+		errors.Is(err, ErrPublishWindowInvalid),
+		errors.Is(err, ErrMediaIDInvalid),
+		errors.Is(err, ErrMediaURLRequired),
+		errors.Is(err, ErrMediaURLInvalid),
+		errors.Is(err, ErrMediaAltTextTooLong),
+		errors.Is(err, ErrMediaDimensionsInvalid),
+		errors.Is(err, ErrMediaPositionInvalid),
+		errors.Is(err, ErrMediaVariantNotFound),
+		errors.Is(err, ErrAsOfInvalid),
+		errors.Is(err, ErrQuoteItemsRequired),
+		errors.Is(err, ErrQuoteTooManyItems),
+		errors.Is(err, ErrQuoteSKURequired),
+		errors.Is(err, ErrQuoteQuantityInvalid),
+		errors.Is(err, ErrTaxClassInvalid),
+		errors.Is(err, ErrImportFormat),
+		errors.Is(err, ErrImportHeader),
+		errors.Is(err, ErrExportFormat),
+		isPricingError(err):
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrProductNotFound),
+		errors.Is(err, ErrVariantNotFound),
+		errors.Is(err, ErrTranslationNotFound),
+		errors.Is(err, ErrMediaNotFound),
+		errors.Is(err, ErrNoPriceAtTime):
+		api.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrProductAlreadyExists),
+		errors.Is(err, ErrVariantAlreadyExists),
+		errors.Is(err, ErrCategoryDeleted),
+		errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrOptionInUse):
+		api.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrImportTooLarge):
+		api.ErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// isPricingError reports whether err comes from an invalid currency, market
+// or country, or one the price history doesn't cover
+func isPricingError(err error) bool {
+	return errors.Is(err, ErrUnknownCurrency) ||
+		errors.Is(err, ErrUnknownMarket) ||
+		errors.Is(err, ErrCurrencyMarketMismatch) ||
+		errors.Is(err, ErrUnknownCountry) ||
+		errors.Is(err, ErrHistoryCurrency)
+}
@@ -1,11 +1,14 @@
 package catalog
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mytheresa/go-hiring-challenge/models"
 	"github.com/shopspring/decimal"
@@ -15,16 +18,30 @@ import (
 
 type mockProductsRepo struct {
 	getByCodeFn     func(string) (*models.Product, error)
-	getPaginationFn func(int, int, string, *float64) ([]models.Product, int64, error)
+	getPublishedFn  func(string) (*models.Product, error)
+	getBySKUsFn     func([]string) ([]models.Product, error)
+	getPaginationFn func(int, int, models.ProductFilter) ([]models.Product, int64, error)
+	categoryFacetFn func(models.ProductFilter) ([]models.CategoryCount, error)
+	priceFacetFn    func(models.ProductFilter, decimal.Decimal) ([]models.PriceBucket, error)
+	createFn        func(*models.Product) error
+	updateFn        func(*models.Product) error
+	deleteFn        func(string) error
+	restoreFn       func(string) error
+	optionsFn       func(uint, []models.ProductOption) error
+	translationFn   func(*models.ProductTranslation) error
+	deleteTransFn   func(uint, string) error
+
+	// entries collects the audit entries passed to the writes
+	entries []*models.AuditEntry
 }
 
 func (m *mockProductsRepo) GetAllProducts() ([]models.Product, error) {
 	return nil, nil
 }
 
-func (m *mockProductsRepo) GetProductsWithPagination(offset, limit int, category string, priceLessThan *float64) ([]models.Product, int64, error) {
+func (m *mockProductsRepo) GetProductsWithPagination(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
 	if m.getPaginationFn != nil {
-		return m.getPaginationFn(offset, limit, category, priceLessThan)
+		return m.getPaginationFn(offset, limit, filter)
 	}
 	return nil, 0, nil
 }
@@ -36,6 +53,184 @@ func (m *mockProductsRepo) GetProductByCode(code string) (*models.Product, error
 	return nil, errors.New("not implemented")
 }
 
+// GetPublishedProductByCode falls back to getByCodeFn, visibility itself is
+// decided by the repository query
+func (m *mockProductsRepo) GetPublishedProductByCode(code string) (*models.Product, error) {
+	if m.getPublishedFn != nil {
+		return m.getPublishedFn(code)
+	}
+	return m.GetProductByCode(code)
+}
+
+func (m *mockProductsRepo) GetPublishedProductsBySKUs(skus []string) ([]models.Product, error) {
+	if m.getBySKUsFn != nil {
+		return m.getBySKUsFn(skus)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) GetCategoryFacets(filter models.ProductFilter) ([]models.CategoryCount, error) {
+	if m.categoryFacetFn != nil {
+		return m.categoryFacetFn(filter)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) GetPriceFacets(filter models.ProductFilter, bucketSize decimal.Decimal) ([]models.PriceBucket, error) {
+	if m.priceFacetFn != nil {
+		return m.priceFacetFn(filter, bucketSize)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) CreateProduct(product *models.Product, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.createFn != nil {
+		return m.createFn(product)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) UpdateProduct(product *models.Product, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.updateFn != nil {
+		return m.updateFn(product)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) DeleteProduct(code string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteFn != nil {
+		return m.deleteFn(code)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) RestoreProduct(code string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.restoreFn != nil {
+		return m.restoreFn(code)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) ReplaceProductOptions(productID uint, options []models.ProductOption, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.optionsFn != nil {
+		return m.optionsFn(productID, options)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) SaveProductTranslation(translation *models.ProductTranslation, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.translationFn != nil {
+		return m.translationFn(translation)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockProductsRepo) DeleteProductTranslation(productID uint, locale string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteTransFn != nil {
+		return m.deleteTransFn(productID, locale)
+	}
+	return errors.New("not implemented")
+}
+
+type mockCategoriesFinder struct {
+	categories map[string]*models.Category
+}
+
+func (m *mockCategoriesFinder) GetCategoryByCode(code string) (*models.Category, error) {
+	if c, ok := m.categories[code]; ok {
+		return c, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *mockCategoriesFinder) GetAllCategories() ([]models.Category, error) {
+	var categories []models.Category
+	for _, c := range m.categories {
+		categories = append(categories, *c)
+	}
+	return categories, nil
+}
+
+var testTaxRates = TaxRates{
+	"DE": {"standard": decimal.NewFromInt(19), "reduced": decimal.NewFromInt(7), "zero": decimal.Zero},
+	"CH": {"standard": decimal.RequireFromString("8.1"), "reduced": decimal.RequireFromString("2.6"), "zero": decimal.Zero},
+}
+
+func newTestCategories() *mockCategoriesFinder {
+	return &mockCategoriesFinder{categories: map[string]*models.Category{
+		"CLOTHING": {ID: 1, Code: "CLOTHING", Name: "Clothing"},
+		"SHOES":    {ID: 2, Code: "SHOES", Name: "Shoes"},
+	}}
+}
+
+type mockPriceLists struct {
+	markets    map[string]string
+	rates      map[string]decimal.Decimal
+	entriesFn  func([]uint, string, string) ([]models.PriceListEntry, error)
+	history    []models.PriceHistoryEntry
+	promotions []models.Promotion
+}
+
+func (m *mockPriceLists) GetMarketByCode(code string) (*models.Market, error) {
+	if currency, ok := m.markets[code]; ok {
+		return &models.Market{Code: code, Currency: currency}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *mockPriceLists) GetExchangeRate(currency string) (decimal.Decimal, error) {
+	if rate, ok := m.rates[currency]; ok {
+		return rate, nil
+	}
+	return decimal.Zero, models.ErrNotFound
+}
+
+func (m *mockPriceLists) GetPriceListEntries(productIDs []uint, currency, market string) ([]models.PriceListEntry, error) {
+	if m.entriesFn != nil {
+		return m.entriesFn(productIDs, currency, market)
+	}
+	return nil, nil
+}
+
+func (m *mockPriceLists) GetPriceHistory(productID uint) ([]models.PriceHistoryEntry, error) {
+	var entries []models.PriceHistoryEntry
+	for _, e := range m.history {
+		if e.ProductID != nil && *e.ProductID == productID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// GetPricesAt picks the periods in effect the same way the repository query does
+func (m *mockPriceLists) GetPricesAt(productID uint, at time.Time) ([]models.PriceHistoryEntry, error) {
+	var entries []models.PriceHistoryEntry
+	for _, e := range m.history {
+		if e.ProductID != nil && *e.ProductID == productID && !e.ValidFrom.After(at) && (e.ValidTo == nil || e.ValidTo.After(at)) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockPriceLists) GetActivePromotions() ([]models.Promotion, error) {
+	return m.promotions, nil
+}
+
+func newTestPriceLists() *mockPriceLists {
+	return &mockPriceLists{
+		markets: map[string]string{"DE": "EUR", "UK": "GBP", "US": "USD"},
+		rates:   map[string]decimal.Decimal{"GBP": decimal.RequireFromString("0.85"), "USD": decimal.RequireFromString("1.08")},
+	}
+}
+
 func TestHandleGetByCode_Success(t *testing.T) {
 	category := &models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}
 	product := &models.Product{
@@ -60,7 +255,7 @@ func TestHandleGetByCode_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
 	req.SetPathValue("code", "PROD001")
 	w := httptest.NewRecorder()
@@ -74,28 +269,225 @@ func TestHandleGetByCode_Success(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 
 	assert.Equal(t, "PROD001", resp.Code)
-	assert.Equal(t, 10.99, resp.Price)
+	assert.Equal(t, 10.99, resp.Price.Amount.InexactFloat64())
 	assert.Equal(t, "CLOTHING", resp.Category.Code)
 	assert.Equal(t, "Clothing", resp.Category.Name)
 
 	require.Len(t, resp.Variants, 3)
 
 	// Variant with explicit price keeps it
-	assert.Equal(t, 11.99, resp.Variants[0].Price)
+	assert.Equal(t, 11.99, resp.Variants[0].Price.Amount.InexactFloat64())
 
 	// Variants with zero price inherit from product
-	assert.Equal(t, 10.99, resp.Variants[1].Price)
-	assert.Equal(t, 10.99, resp.Variants[2].Price)
+	assert.Equal(t, 10.99, resp.Variants[1].Price.Amount.InexactFloat64())
+	assert.Equal(t, 10.99, resp.Variants[2].Price.Amount.InexactFloat64())
+}
+
+func TestHandleGetByCode_ExactPrices(t *testing.T) {
+	product := &models.Product{
+		ID:    1,
+		Code:  "PROD001",
+		Price: decimal.RequireFromString("10.99"),
+		Variants: []models.Variant{
+			{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("12"), Quantity: 3},
+			{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+		},
+	}
+
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return product, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog/PROD001?priceFormat=exact", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetByCode(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"code": "PROD001",
+		"price": {"amount": "10.99", "currency": "EUR"},
+		"variants": [
+			{"name": "Red", "sku": "SKU001-R", "price": {"amount": "12.00", "currency": "EUR"}, "quantity": 3, "available": true},
+			{"name": "Blue", "sku": "SKU001-B", "price": {"amount": "10.99", "currency": "EUR"}, "quantity": 0, "available": false}
+		]
+	}`, w.Body.String())
+}
+
+func TestHandleGet_ExactPrices(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return []models.Product{{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99")}}, 1, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("exact", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?priceFormat=exact", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"price":{"amount":"10.99","currency":"EUR"}`)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?priceFormat=string", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleGetByCode_Currency(t *testing.T) {
+	product := &models.Product{
+		ID:    1,
+		Code:  "PROD001",
+		Price: decimal.RequireFromString("10.00"),
+		Variants: []models.Variant{
+			{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("12.00")},
+			{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+			{ID: 3, ProductID: 1, Name: "Green", SKU: "SKU001-G"},
+		},
+	}
+
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return product, nil
+		},
+	}
+
+	variantID := uint(3)
+	market := "US"
+	prices := newTestPriceLists()
+	prices.entriesFn = func(productIDs []uint, currency, m string) ([]models.PriceListEntry, error) {
+		assert.Equal(t, []uint{1}, productIDs)
+		switch currency {
+		case "GBP":
+			return []models.PriceListEntry{
+				{ProductID: 1, VariantID: &variantID, Currency: "GBP", Amount: decimal.RequireFromString("7.95")},
+			}, nil
+		case "USD":
+			assert.Equal(t, "US", m)
+			return []models.PriceListEntry{
+				{ProductID: 1, Currency: "USD", Amount: decimal.RequireFromString("11.50")},
+				{ProductID: 1, Currency: "USD", Market: &market, Amount: decimal.RequireFromString("11.99")},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), prices, testTaxRates))
+
+	t.Run("converted with the exchange rate", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?currency=gbp&priceFormat=exact", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"code": "PROD001",
+			"price": {"amount": "8.50", "currency": "GBP"},
+			"variants": [
+				{"name": "Red", "sku": "SKU001-R", "price": {"amount": "10.20", "currency": "GBP"}, "quantity": 0, "available": false},
+				{"name": "Blue", "sku": "SKU001-B", "price": {"amount": "8.50", "currency": "GBP"}, "quantity": 0, "available": false},
+				{"name": "Green", "sku": "SKU001-G", "price": {"amount": "7.95", "currency": "GBP"}, "quantity": 0, "available": false}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("market price list wins and variants inherit it", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?market=US&priceFormat=exact", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "11.99", resp.Price.Amount.StringFixed(2))
+		assert.Equal(t, "USD", resp.Price.Currency)
+		assert.Equal(t, "12.96", resp.Variants[0].Price.Amount.StringFixed(2))
+		assert.Equal(t, "11.99", resp.Variants[1].Price.Amount.StringFixed(2))
+	})
+
+	for name, query := range map[string]string{
+		"unknown currency": "currency=JPY",
+		"unknown market":   "market=XX",
+		"market mismatch":  "market=UK&currency=USD",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/catalog/PROD001?"+query, nil)
+			req.SetPathValue("code", "PROD001")
+			w := httptest.NewRecorder()
+
+			handler.HandleGetByCode(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleGet_Currency(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			// Price filters stay in the response currency, the repository
+			// resolves the prices of the view like the response shows them
+			require.NotNil(t, filter.PriceMin)
+			assert.True(t, decimal.RequireFromString("8.5").Equal(*filter.PriceMin))
+			assert.Equal(t, "GBP", filter.Prices.Currency)
+			assert.Empty(t, filter.Prices.Market)
+			assert.Equal(t, "0.85", filter.Prices.Rate.String())
+			return []models.Product{{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99")}}, 1, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?currency=GBP&priceMin=8.5&priceFormat=exact", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"price":{"amount":"9.34","currency":"GBP"}`)
+}
+
+func TestHandleGetByCode_LowerCaseCode(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code == "PROD010" {
+				return &models.Product{ID: 10, Code: "PROD010", Price: decimal.NewFromInt(5)}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog/prod010", nil)
+	req.SetPathValue("code", "prod010")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetByCode(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"PROD010"`)
 }
 
 func TestHandleGetByCode_NotFound(t *testing.T) {
 	repo := &mockProductsRepo{
 		getByCodeFn: func(code string) (*models.Product, error) {
-			return nil, errors.New("not found")
+			return nil, models.ErrNotFound
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog/INVALID", nil)
 	req.SetPathValue("code", "INVALID")
 	w := httptest.NewRecorder()
@@ -105,8 +497,26 @@ func TestHandleGetByCode_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestHandleGetByCode_RepositoryError(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetByCode(w, req)
+
+	// A failing database is not a missing product
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
 func TestHandleGetByCode_EmptyCode(t *testing.T) {
-	handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}))
+	handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog/", nil)
 	req.SetPathValue("code", "")
 	w := httptest.NewRecorder()
@@ -132,7 +542,7 @@ func TestHandleGetByCode_NoCategory(t *testing.T) {
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog/NOCATEGORY", nil)
 	req.SetPathValue("code", "NOCATEGORY")
 	w := httptest.NewRecorder()
@@ -166,7 +576,7 @@ func TestHandleGetByCode_NoVariants(t *testing.T) {
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog/SIMPLE", nil)
 	req.SetPathValue("code", "SIMPLE")
 	w := httptest.NewRecorder()
@@ -179,7 +589,7 @@ func TestHandleGetByCode_NoVariants(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &resp)
 
 	assert.Equal(t, "SIMPLE", resp.Code)
-	assert.Equal(t, 50.00, resp.Price)
+	assert.Equal(t, 50.00, resp.Price.Amount.InexactFloat64())
 	assert.Empty(t, resp.Variants)
 }
 
@@ -203,12 +613,12 @@ func TestHandleGet_Success(t *testing.T) {
 	}
 
 	repo := &mockProductsRepo{
-		getPaginationFn: func(offset, limit int, category string, priceLessThan *float64) ([]models.Product, int64, error) {
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
 			return products, 2, nil
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog?limit=10&offset=0", nil)
 	w := httptest.NewRecorder()
 
@@ -220,12 +630,13 @@ func TestHandleGet_Success(t *testing.T) {
 	var resp PaginatedResponse
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 
-	assert.Equal(t, int64(2), resp.Total)
+	require.NotNil(t, resp.Total)
+	assert.Equal(t, int64(2), *resp.Total)
 	assert.Equal(t, 0, resp.Offset)
 	assert.Equal(t, 10, resp.Limit)
 	require.Len(t, resp.Products, 2)
 	assert.Equal(t, "PROD001", resp.Products[0].Code)
-	assert.Equal(t, 10.99, resp.Products[0].Price)
+	assert.Equal(t, 10.99, resp.Products[0].Price.Amount.InexactFloat64())
 }
 
 func TestHandleGet_WithFilters(t *testing.T) {
@@ -241,18 +652,19 @@ func TestHandleGet_WithFilters(t *testing.T) {
 	}
 
 	repo := &mockProductsRepo{
-		getPaginationFn: func(offset, limit int, cat string, priceLessThan *float64) ([]models.Product, int64, error) {
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
 			// Verify filters are passed correctly
-			assert.Equal(t, "CLOTHING", cat)
-			assert.NotNil(t, priceLessThan)
-			assert.Equal(t, 15.0, *priceLessThan)
+			assert.Equal(t, "CLOTHING", filter.Category)
+			assert.False(t, filter.IncludeSubcategories)
+			assert.NotNil(t, filter.PriceLessThan)
+			assert.True(t, decimal.NewFromInt(15).Equal(*filter.PriceLessThan))
 			assert.Equal(t, 0, offset)
 			assert.Equal(t, 10, limit)
 			return filteredProducts, 1, nil
 		},
 	}
 
-	handler := NewCatalogHandler(NewCatalogService(repo))
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
 	req := httptest.NewRequest("GET", "/catalog?category=CLOTHING&priceLessThan=15.00", nil)
 	w := httptest.NewRecorder()
 
@@ -263,8 +675,1655 @@ func TestHandleGet_WithFilters(t *testing.T) {
 	var resp PaginatedResponse
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 
-	assert.Equal(t, int64(1), resp.Total)
+	require.NotNil(t, resp.Total)
+	assert.Equal(t, int64(1), *resp.Total)
 	assert.Len(t, resp.Products, 1)
 	assert.Equal(t, "PROD001", resp.Products[0].Code)
 	assert.Equal(t, "CLOTHING", resp.Products[0].Category.Code)
 }
+
+func TestHandleGet_PriceListSort(t *testing.T) {
+	var lastFilter models.ProductFilter
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			lastFilter = filter
+			return []models.Product{
+				{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10")},
+				{ID: 2, Code: "PROD002", Price: decimal.RequireFromString("20")},
+			}, 3, nil
+		},
+	}
+
+	market := "UK"
+	prices := newTestPriceLists()
+	prices.entriesFn = func(productIDs []uint, currency, m string) ([]models.PriceListEntry, error) {
+		return []models.PriceListEntry{
+			{ProductID: 2, Currency: "GBP", Amount: decimal.RequireFromString("12.00")},
+			{ProductID: 2, Currency: "GBP", Market: &market, Amount: decimal.RequireFromString("13.50")},
+		}, nil
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), prices, testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?market=UK&sort=price&priceMax=20&limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The bounds are GBP amounts compared with the prices of the UK market
+	assert.Equal(t, models.PriceView{Currency: "GBP", Market: "UK", Rate: decimal.RequireFromString("0.85")}, lastFilter.Prices)
+	assert.True(t, decimal.NewFromInt(20).Equal(*lastFilter.PriceMax))
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Products, 2)
+	assert.Equal(t, "13.5", resp.Products[1].Price.Amount.String())
+
+	// The cursor continues after the market price the response showed, not
+	// after the converted base price
+	w = httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?market=UK&sort=price&priceMax=20&limit=2&cursor="+resp.NextCursor, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, lastFilter.After, 2)
+	assert.True(t, decimal.RequireFromString("13.5").Equal(lastFilter.After[0].(decimal.Decimal)))
+}
+
+func TestHandleGet_IncludeSubcategories(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			assert.Equal(t, "CLOTHING", filter.Category)
+			assert.True(t, filter.IncludeSubcategories)
+			return nil, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog?category=CLOTHING&includeSubcategories=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandleGet_InStock(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			assert.True(t, filter.InStock)
+			return nil, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog?inStock=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandleGet_AttributeFilter(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			assert.Equal(t, map[string][]string{
+				"color": {"red", "blue"},
+				"size":  {"m"},
+			}, filter.Attributes)
+			return nil, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("valid", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?attr.color=Red,blue&attr.Size=M", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	for name, query := range map[string]string{
+		"missing name":  "attr.=red",
+		"missing value": "attr.color=",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?"+query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleGet_Search(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			assert.Equal(t, "sku001 red", filter.Search)
+			return []models.Product{
+				{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99), SearchRank: 0.6},
+				{ID: 2, Code: "PROD002", Price: decimal.NewFromFloat(12.49), SearchRank: 0.2},
+			}, 2, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog?q=+sku001+red+", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Products, 2)
+	require.NotNil(t, resp.Products[0].Score)
+	assert.Equal(t, 0.6, *resp.Products[0].Score)
+	assert.Equal(t, 0.2, *resp.Products[1].Score)
+}
+
+func TestHandleGet_NoScoreWithoutSearch(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return []models.Product{{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}}, 1, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "score")
+}
+
+func TestHandleGet_PriceRange(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			require.NotNil(t, filter.PriceMin)
+			require.NotNil(t, filter.PriceMax)
+			assert.True(t, decimal.RequireFromString("10.99").Equal(*filter.PriceMin))
+			assert.True(t, decimal.RequireFromString("15").Equal(*filter.PriceMax))
+			assert.Nil(t, filter.PriceLessThan)
+			assert.True(t, filter.MatchVariantPrices)
+			return nil, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?priceMin=10.99&priceMax=15&priceMatch=variant", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandleGet_InvalidPriceFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"malformed priceLessThan", "priceLessThan=abc"},
+		{"malformed priceMin", "priceMin=1,5"},
+		{"negative priceMax", "priceMax=-1"},
+		{"min above max", "priceMin=20&priceMax=10"},
+		{"unknown price match", "priceMin=1&priceMatch=cheapest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
+			w := httptest.NewRecorder()
+
+			handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?"+tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleGet_Facets(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return []models.Product{{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}}, 1, nil
+		},
+		categoryFacetFn: func(filter models.ProductFilter) ([]models.CategoryCount, error) {
+			assert.Equal(t, "prod", filter.Search)
+			return []models.CategoryCount{
+				{Code: "CLOTHING", Name: "Clothing", Count: 3},
+				{Code: "SHOES", Name: "Shoes", Count: 2},
+			}, nil
+		},
+		priceFacetFn: func(filter models.ProductFilter, bucketSize decimal.Decimal) ([]models.PriceBucket, error) {
+			assert.Equal(t, "prod", filter.Search)
+			assert.True(t, decimal.NewFromInt(5).Equal(bucketSize))
+			return []models.PriceBucket{
+				{Min: decimal.NewFromInt(5), Max: decimal.NewFromInt(10), Count: 2},
+				{Min: decimal.NewFromInt(10), Max: decimal.NewFromInt(15), Count: 3},
+			}, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?q=prod&facets=category,price&priceBucketSize=5", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Facets)
+	require.Len(t, resp.Facets.Categories, 2)
+	assert.Equal(t, CategoryFacet{Code: "CLOTHING", Name: "Clothing", Count: 3}, resp.Facets.Categories[0])
+	require.Len(t, resp.Facets.Price, 2)
+	assert.Equal(t, 10.0, resp.Facets.Price[1].Min.Amount.InexactFloat64())
+	assert.Equal(t, 15.0, resp.Facets.Price[1].Max.Amount.InexactFloat64())
+	assert.Equal(t, int64(3), resp.Facets.Price[1].Count)
+}
+
+func TestHandleGet_InvalidFacets(t *testing.T) {
+	for _, query := range []string{"facets=brand", "facets=price&priceBucketSize=0", "facets=price&priceBucketSize=abc"} {
+		handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
+		w := httptest.NewRecorder()
+
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?"+query, nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestHandleGet_Sort(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		sort   string
+	}{
+		{"default sort", "", http.StatusOK, "id"},
+		{"multiple keys", "?sort=-price,code", http.StatusOK, "-price,code,id"},
+		{"explicit id", "?sort=-id", http.StatusOK, "-id"},
+		{"relevance by default when searching", "?q=prod", http.StatusOK, "-relevance,id"},
+		{"price while searching", "?q=prod&sort=price", http.StatusOK, "price,id"},
+		{"unknown field", "?sort=name", http.StatusBadRequest, ""},
+		{"relevance without search", "?sort=-relevance", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockProductsRepo{
+				getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+					assert.Equal(t, tt.sort, formatSort(filter.Sort))
+					return nil, 0, nil
+				},
+			}
+
+			handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+			w := httptest.NewRecorder()
+
+			handler.HandleGet(w, httptest.NewRequest("GET", "/catalog"+tt.query, nil))
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var resp PaginatedResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.sort, resp.Sort)
+			}
+		})
+	}
+}
+
+func TestHandleGet_Cursor(t *testing.T) {
+	var lastFilter models.ProductFilter
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			lastFilter = filter
+			if filter.After != nil {
+				return []models.Product{{ID: 3, Code: "PROD003", Price: decimal.NewFromFloat(8.75)}}, 3, nil
+			}
+			return []models.Product{
+				{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(12.49)},
+				{ID: 2, Code: "PROD002", Price: decimal.NewFromFloat(10.99)},
+			}, 3, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=2&sort=-price", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var first PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	require.NotEmpty(t, first.NextCursor)
+
+	w = httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=2&sort=-price&cursor="+first.NextCursor, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, lastFilter.After, 2)
+	assert.True(t, decimal.NewFromFloat(10.99).Equal(lastFilter.After[0].(decimal.Decimal)))
+	assert.Equal(t, uint(2), lastFilter.After[1])
+
+	var second PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	require.Len(t, second.Products, 1)
+	assert.Empty(t, second.NextCursor)
+
+	t.Run("cursor from another sort", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=2&sort=code&cursor="+first.NextCursor, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?cursor=not-a-cursor", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleGet_WithoutTotal(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			assert.True(t, filter.SkipTotal)
+			return []models.Product{{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}}, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=1&includeTotal=false", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Nil(t, resp.Total)
+	assert.NotEmpty(t, resp.NextCursor)
+}
+
+func TestHandleCreate_Success(t *testing.T) {
+	repo := &mockProductsRepo{
+		createFn: func(product *models.Product) error {
+			assert.Equal(t, "PROD100", product.Code)
+			require.NotNil(t, product.CategoryID)
+			assert.Equal(t, uint(2), *product.CategoryID)
+			product.ID = 100
+			return nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	body := `{"code":"prod100","price":"19.90","category":"shoes"}`
+	req := httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "PROD100", resp.Code)
+	assert.Equal(t, 19.90, resp.Price.Amount.InexactFloat64())
+	assert.Equal(t, "SHOES", resp.Category.Code)
+	assert.Equal(t, models.ProductStatusDraft, resp.Status)
+	assert.Empty(t, resp.Variants)
+}
+
+func TestHandleCreate_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `invalid json`},
+		{"missing code", `{"price":"10.00"}`},
+		{"code too long", `{"code":"` + strings.Repeat("A", 33) + `","price":"10.00"}`},
+		{"missing price", `{"code":"PROD100"}`},
+		{"negative price", `{"code":"PROD100","price":"-1.00"}`},
+		{"too many decimals", `{"code":"PROD100","price":"1.999"}`},
+		{"price too large", `{"code":"PROD100","price":"100000000"}`},
+		{"unknown category", `{"code":"PROD100","price":"10.00","category":"UNKNOWN"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
+			req := httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.HandleCreate(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleCreate_Duplicate(t *testing.T) {
+	repo := &mockProductsRepo{
+		createFn: func(product *models.Product) error {
+			return models.ErrDuplicateKey
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(`{"code":"PROD001","price":"10.00"}`))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestHandleUpdate_ReplacesCategory(t *testing.T) {
+	category := &models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99), CategoryID: &category.ID, Category: category}, nil
+		},
+		updateFn: func(product *models.Product) error {
+			assert.True(t, decimal.RequireFromString("12.50").Equal(product.Price))
+			// PUT without a category clears it
+			assert.Nil(t, product.CategoryID)
+			return nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("PUT", "/catalog/PROD001", bytes.NewBufferString(`{"price":"12.50"}`))
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 12.50, resp.Price.Amount.InexactFloat64())
+	assert.Nil(t, resp.Category)
+}
+
+func TestHandleUpdate_MissingPrice(t *testing.T) {
+	handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("PUT", "/catalog/PROD001", bytes.NewBufferString(`{"category":"SHOES"}`))
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlePatch_KeepsPrice(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{ID: 1, Code: "PROD001", Price: decimal.NewFromFloat(10.99)}, nil
+		},
+		updateFn: func(product *models.Product) error {
+			require.NotNil(t, product.CategoryID)
+			assert.Equal(t, uint(2), *product.CategoryID)
+			return nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("PATCH", "/catalog/PROD001", bytes.NewBufferString(`{"category":"SHOES"}`))
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandlePatch(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 10.99, resp.Price.Amount.InexactFloat64())
+	assert.Equal(t, "SHOES", resp.Category.Code)
+}
+
+func TestHandlePatch_AuditEntry(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{
+				ID:       1,
+				Code:     "PROD001",
+				Price:    decimal.RequireFromString("10.99"),
+				Brand:    "Acme",
+				Status:   models.ProductStatusActive,
+				Category: &models.Category{ID: 1, Code: "CLOTHING"},
+			}, nil
+		},
+		updateFn: func(product *models.Product) error {
+			return nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("PATCH", "/catalog/PROD001", bytes.NewBufferString(`{"price":"12.50","brand":"Acme"}`))
+	req.SetPathValue("code", "PROD001")
+	req.Header.Set("X-Actor", "jane@example.com")
+	req.Header.Set("X-Request-ID", "req-42")
+	w := httptest.NewRecorder()
+
+	handler.HandlePatch(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, repo.entries, 1)
+
+	entry := repo.entries[0]
+	assert.Equal(t, "jane@example.com", entry.Actor)
+	assert.Equal(t, "req-42", entry.RequestID)
+	assert.Equal(t, models.AuditActionUpdate, entry.Action)
+	assert.Equal(t, models.AuditEntityProduct, entry.Entity)
+	assert.Equal(t, "PROD001", entry.Code)
+
+	// Only the price changed, the unchanged brand isn't part of the diff
+	assert.Equal(t, map[string]models.AuditChange{
+		"price": {Before: "10.99", After: "12.50"},
+	}, entry.Diff())
+}
+
+func TestHandlePatch_NotFound(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return nil, models.ErrNotFound
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("PATCH", "/catalog/INVALID", bytes.NewBufferString(`{"price":"1.00"}`))
+	req.SetPathValue("code", "INVALID")
+	w := httptest.NewRecorder()
+
+	handler.HandlePatch(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleDelete(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code == "PROD001" {
+				return &models.Product{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"), Status: models.ProductStatusActive}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+		deleteFn: func(code string) error {
+			assert.Equal(t, "PROD001", code)
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("existing product", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleDelete(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		require.Len(t, repo.entries, 1)
+		assert.Equal(t, models.AuditActionDelete, repo.entries[0].Action)
+		assert.Equal(t, "10.99", repo.entries[0].Before["price"])
+		assert.Nil(t, repo.entries[0].After)
+	})
+
+	t.Run("lower case code", func(t *testing.T) {
+		// Codes are stored upper case, like POST /catalog creates them
+		req := httptest.NewRequest("DELETE", "/catalog/prod001", nil)
+		req.SetPathValue("code", "prod001")
+		w := httptest.NewRecorder()
+
+		handler.HandleDelete(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/catalog/INVALID", nil)
+		req.SetPathValue("code", "INVALID")
+		w := httptest.NewRecorder()
+
+		handler.HandleDelete(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleGetByCode_OptionsAndAttributes(t *testing.T) {
+	color := models.ProductOption{ID: 1, Name: "Color", Values: []models.ProductOptionValue{
+		{ID: 11, Value: "Red"},
+		{ID: 12, Value: "Blue"},
+	}}
+	size := models.ProductOption{ID: 2, Name: "Size", Values: []models.ProductOptionValue{
+		{ID: 21, Value: "S"},
+	}}
+
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{
+				ID:      1,
+				Code:    "PROD001",
+				Price:   decimal.RequireFromString("10.99"),
+				Options: []models.ProductOption{color, size},
+				Variants: []models.Variant{
+					{ID: 1, Name: "Red S", SKU: "SKU001-R", Attributes: []models.VariantAttribute{
+						{OptionID: 1, Option: &color, OptionValueID: 11, OptionValue: &color.Values[0]},
+						{OptionID: 2, Option: &size, OptionValueID: 21, OptionValue: &size.Values[0]},
+					}},
+					{ID: 2, Name: "Plain", SKU: "SKU001-P"},
+				},
+			}, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetByCode(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"code": "PROD001",
+		"price": 10.99,
+		"options": [
+			{"name": "Color", "values": ["Red", "Blue"]},
+			{"name": "Size", "values": ["S"]}
+		],
+		"variants": [
+			{"name": "Red S", "sku": "SKU001-R", "price": 10.99, "quantity": 0, "available": false,
+				"attributes": {"Color": "Red", "Size": "S"}},
+			{"name": "Plain", "sku": "SKU001-P", "price": 10.99, "quantity": 0, "available": false}
+		]
+	}`, w.Body.String())
+}
+
+func TestHandleReplaceOptions(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{ID: 1, Code: code}, nil
+		},
+		optionsFn: func(productID uint, options []models.ProductOption) error {
+			assert.Equal(t, uint(1), productID)
+			if len(options) == 1 && options[0].Name == "Color" {
+				return models.ErrReferenced
+			}
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"options":[{"name":" Size ","values":["S","M"]},{"name":"Color","values":["Red"]}]}`, http.StatusOK},
+		{"no options", `{"options":[]}`, http.StatusOK},
+		{"missing name", `{"options":[{"name":"","values":["S"]}]}`, http.StatusBadRequest},
+		{"no values", `{"options":[{"name":"Size","values":[]}]}`, http.StatusBadRequest},
+		{"duplicate option", `{"options":[{"name":"Size","values":["S"]},{"name":"size","values":["M"]}]}`, http.StatusBadRequest},
+		{"duplicate value", `{"options":[{"name":"Size","values":["S","s"]}]}`, http.StatusBadRequest},
+		{"removes used value", `{"options":[{"name":"Color","values":["Red"]}]}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/catalog/PROD001/options", bytes.NewBufferString(tt.body))
+			req.SetPathValue("code", "PROD001")
+			w := httptest.NewRecorder()
+
+			handler.HandleReplaceOptions(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	t.Run("response", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/catalog/PROD001/options", bytes.NewBufferString(`{"options":[{"name":" Size ","values":["S"," M"]}]}`))
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleReplaceOptions(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"options":[{"name":"Size","values":["S","M"]}]}`, w.Body.String())
+	})
+}
+
+func TestHandleGetByCode_Localized(t *testing.T) {
+	product := &models.Product{
+		ID:    1,
+		Code:  "PROD001",
+		Brand: "Acme",
+		Price: decimal.RequireFromString("10.99"),
+		Category: &models.Category{
+			ID: 1, Code: "CLOTHING", Name: "Clothing",
+			Translations: []models.CategoryTranslation{{CategoryID: 1, Locale: "de", Name: "Kleidung"}},
+		},
+		Translations: []models.ProductTranslation{
+			{ProductID: 1, Locale: "en", Title: "Shirt", Description: "A shirt"},
+			{ProductID: 1, Locale: "de", Title: "Hemd", Description: "Ein Hemd"},
+		},
+	}
+
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return product, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		locale         string
+		title          string
+		category       string
+	}{
+		{"default locale", "/catalog/PROD001", "", "en", "Shirt", "Clothing"},
+		{"accept language", "/catalog/PROD001", "fr;q=0.9, de;q=0.8", "de", "Hemd", "Kleidung"},
+		{"region falls back to language", "/catalog/PROD001", "de-CH", "de", "Hemd", "Kleidung"},
+		{"query parameter wins", "/catalog/PROD001?locale=en-GB", "de", "en", "Shirt", "Clothing"},
+		{"untranslated locale", "/catalog/PROD001?locale=it", "", "en", "Shirt", "Clothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			req.SetPathValue("code", "PROD001")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+
+			handler.HandleGetByCode(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp ProductDetail
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.locale, resp.Locale)
+			assert.Equal(t, tt.title, resp.Title)
+			assert.Equal(t, "Acme", resp.Brand)
+			assert.Equal(t, tt.category, resp.Category.Name)
+		})
+	}
+
+	t.Run("invalid locale", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?locale=d3", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleGet_Localized(t *testing.T) {
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return []models.Product{
+				{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"), Translations: []models.ProductTranslation{
+					{ProductID: 1, Locale: "fr", Title: "Chemise"},
+				}},
+				{ID: 2, Code: "PROD002", Price: decimal.RequireFromString("12.49")},
+			}, 2, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	req := httptest.NewRequest("GET", "/catalog", nil)
+	req.Header.Set("Accept-Language", "fr-FR")
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Products, 2)
+	assert.Equal(t, "Chemise", resp.Products[0].Title)
+	assert.Equal(t, "fr", resp.Products[0].Locale)
+
+	// Products without any matching translation have no title
+	assert.Empty(t, resp.Products[1].Title)
+	assert.Empty(t, resp.Products[1].Locale)
+}
+
+func TestHandleSaveTranslation(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code == "PROD001" {
+				return &models.Product{ID: 1, Code: code}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+		translationFn: func(translation *models.ProductTranslation) error {
+			assert.Equal(t, uint(1), translation.ProductID)
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name   string
+		code   string
+		locale string
+		body   string
+		status int
+	}{
+		{"valid", "PROD001", "de-ch", `{"title":" Hemd ","description":"Ein Hemd"}`, http.StatusOK},
+		{"missing title", "PROD001", "de", `{"title":" "}`, http.StatusBadRequest},
+		{"invalid locale", "PROD001", "german!", `{"title":"Hemd"}`, http.StatusBadRequest},
+		{"unknown product", "UNKNOWN", "de", `{"title":"Hemd"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/catalog/"+tt.code+"/translations/"+tt.locale, bytes.NewBufferString(tt.body))
+			req.SetPathValue("code", tt.code)
+			req.SetPathValue("locale", tt.locale)
+			w := httptest.NewRecorder()
+
+			handler.HandleSaveTranslation(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"locale":"de-CH","title":"Hemd","description":"Ein Hemd"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleDeleteTranslation(t *testing.T) {
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{ID: 1, Code: code}, nil
+		},
+		deleteTransFn: func(productID uint, locale string) error {
+			if locale == "de" {
+				return nil
+			}
+			return models.ErrNotFound
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name   string
+		locale string
+		status int
+	}{
+		{"existing translation", "DE", http.StatusNoContent},
+		{"missing translation", "fr", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/catalog/PROD001/translations/"+tt.locale, nil)
+			req.SetPathValue("code", "PROD001")
+			req.SetPathValue("locale", tt.locale)
+			w := httptest.NewRecorder()
+
+			handler.HandleDeleteTranslation(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandleGet_PrimaryImageAndGallery(t *testing.T) {
+	variant := &models.Variant{ID: 7, ProductID: 1, Name: "Red", SKU: "SKU001-R"}
+	product := models.Product{
+		ID:    1,
+		Code:  "PROD001",
+		Price: decimal.RequireFromString("10.99"),
+		Media: []models.ProductMedia{
+			{ID: 1, ProductID: 1, URL: "https://media.example.com/front.jpg", AltText: "Front", Width: 1200, Height: 1600},
+			{ID: 2, ProductID: 1, VariantID: &variant.ID, Variant: variant, URL: "https://media.example.com/red.jpg", Position: 1},
+		},
+	}
+
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			// The repository only preloads the first image for lists
+			p := product
+			p.Media = p.Media[:1]
+			return []models.Product{p}, 1, nil
+		},
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &product, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Products, 1)
+		require.NotNil(t, resp.Products[0].PrimaryImage)
+		assert.Equal(t, "https://media.example.com/front.jpg", resp.Products[0].PrimaryImage.URL)
+		assert.Equal(t, "Front", resp.Products[0].PrimaryImage.AltText)
+	})
+
+	t.Run("detail", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Gallery, 2)
+		assert.Empty(t, resp.Gallery[0].Variant)
+		assert.Equal(t, "SKU001-R", resp.Gallery[1].Variant)
+		assert.Equal(t, 1, resp.Gallery[1].Position)
+	})
+}
+
+func TestHandleGet_Visibility(t *testing.T) {
+	var got models.ProductFilter
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			got = filter
+			return nil, 0, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("public catalog", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?status=draft", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, got.IncludeUnpublished)
+		assert.Empty(t, got.Status)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleAdminGet(w, httptest.NewRequest("GET", "/admin/catalog?status=Draft", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, got.IncludeUnpublished)
+		assert.Equal(t, models.ProductStatusDraft, got.Status)
+	})
+
+	t.Run("admin with invalid status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleAdminGet(w, httptest.NewRequest("GET", "/admin/catalog?status=deleted", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleGetByCode_Visibility(t *testing.T) {
+	publishFrom := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{
+				ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"),
+				Status: models.ProductStatusActive, PublishFrom: &publishFrom,
+			}, nil
+		},
+		getPublishedFn: func(code string) (*models.Product, error) {
+			return nil, models.ErrNotFound
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	t.Run("public catalog", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleAdminGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, models.ProductStatusActive, resp.Status)
+		require.NotNil(t, resp.PublishFrom)
+		assert.True(t, publishFrom.Equal(*resp.PublishFrom))
+	})
+}
+
+func TestHandleCreate_Status(t *testing.T) {
+	repo := &mockProductsRepo{
+		createFn: func(product *models.Product) error {
+			assert.Equal(t, models.ProductStatusActive, product.Status)
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"active with window", `{"code":"PROD100","price":"19.90","status":"ACTIVE","publish_from":"2030-01-01T00:00:00Z","publish_until":"2030-02-01T00:00:00Z"}`, http.StatusCreated},
+		{"unknown status", `{"code":"PROD100","price":"19.90","status":"deleted"}`, http.StatusBadRequest},
+		{"window ends before it starts", `{"code":"PROD100","price":"19.90","status":"active","publish_from":"2030-02-01T00:00:00Z","publish_until":"2030-01-01T00:00:00Z"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleCreate(w, httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandlePatch_PublishWindow(t *testing.T) {
+	publishFrom := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var updated *models.Product
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{
+				ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"),
+				Status: models.ProductStatusDraft, PublishFrom: &publishFrom,
+			}, nil
+		},
+		updateFn: func(product *models.Product) error {
+			updated = product
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	patch := func(body string) int {
+		req := httptest.NewRequest("PATCH", "/catalog/PROD001", bytes.NewBufferString(body))
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+		handler.HandlePatch(w, req)
+		return w.Code
+	}
+
+	t.Run("publishes and keeps the start", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, patch(`{"status":"active","publish_until":"2030-03-01T00:00:00+01:00"}`))
+		assert.Equal(t, models.ProductStatusActive, updated.Status)
+		require.NotNil(t, updated.PublishFrom)
+		require.NotNil(t, updated.PublishUntil)
+	})
+
+	t.Run("clears the start", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, patch(`{"publish_from":""}`))
+		assert.Nil(t, updated.PublishFrom)
+		assert.Equal(t, models.ProductStatusDraft, updated.Status)
+	})
+
+	t.Run("end before the kept start", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, patch(`{"publish_until":"2029-12-31T00:00:00Z"}`))
+	})
+
+	t.Run("malformed time", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, patch(`{"publish_from":"tomorrow"}`))
+	})
+}
+
+func TestHandleRestore(t *testing.T) {
+	tests := []struct {
+		name       string
+		restoreErr error
+		status     int
+	}{
+		{"deleted product", nil, http.StatusOK},
+		{"no deleted product", models.ErrNotFound, http.StatusNotFound},
+		{"code taken again", models.ErrDuplicateKey, http.StatusConflict},
+		{"category deleted", models.ErrCategoryDeleted, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := false
+			repo := &mockProductsRepo{
+				restoreFn: func(code string) error {
+					assert.Equal(t, "PROD001", code)
+					restored = tt.restoreErr == nil
+					return tt.restoreErr
+				},
+				getByCodeFn: func(code string) (*models.Product, error) {
+					if !restored {
+						return nil, models.ErrNotFound
+					}
+					return &models.Product{ID: 1, Code: code, Price: decimal.RequireFromString("10.99"), Status: models.ProductStatusActive}, nil
+				},
+			}
+
+			handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+			req := httptest.NewRequest("POST", "/admin/catalog/PROD001/restore", nil)
+			req.SetPathValue("code", "PROD001")
+			w := httptest.NewRecorder()
+
+			handler.HandleRestore(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var resp ProductDetail
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "PROD001", resp.Code)
+				assert.Equal(t, models.ProductStatusActive, resp.Status)
+			}
+		})
+	}
+}
+
+// newHistoryTestPriceLists has PROD001 at 10.00 until March, then 12.00. Its
+// variant SKU001-R inherited the product price until February and costs 15.00
+// since, SKU001-B was only added in April. SKU001-G was purged since.
+func newHistoryTestPriceLists() *mockPriceLists {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	product := uint(1)
+	red := uint(1)
+	blue := uint(2)
+	redSKU, blueSKU, greenSKU := "SKU001-R", "SKU001-B", "SKU001-G"
+
+	prices := newTestPriceLists()
+	prices.history = []models.PriceHistoryEntry{
+		{ProductID: &product, ProductCode: "PROD001", Amount: decimal.NewNullDecimal(decimal.RequireFromString("10.00")), ValidFrom: jan, ValidTo: &mar},
+		{ProductID: &product, VariantID: &red, ProductCode: "PROD001", SKU: &redSKU, ValidFrom: jan, ValidTo: &feb},
+		// A purged variant keeps its history under its sku
+		{ProductID: &product, ProductCode: "PROD001", SKU: &greenSKU, Amount: decimal.NewNullDecimal(decimal.RequireFromString("9.00")), ValidFrom: jan},
+		{ProductID: &product, VariantID: &red, ProductCode: "PROD001", SKU: &redSKU, Amount: decimal.NewNullDecimal(decimal.RequireFromString("15.00")), ValidFrom: feb},
+		{ProductID: &product, ProductCode: "PROD001", Amount: decimal.NewNullDecimal(decimal.RequireFromString("12.00")), ValidFrom: mar},
+		{ProductID: &product, VariantID: &blue, ProductCode: "PROD001", SKU: &blueSKU, ValidFrom: apr},
+	}
+	return prices
+}
+
+func newHistoryTestProducts() *mockProductsRepo {
+	return &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code != "PROD001" {
+				return nil, models.ErrNotFound
+			}
+			return &models.Product{
+				ID:    1,
+				Code:  "PROD001",
+				Price: decimal.RequireFromString("12.00"),
+				Variants: []models.Variant{
+					{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("15.00")},
+					{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestHandleGetByCode_AsOf(t *testing.T) {
+	handler := NewCatalogHandler(NewCatalogService(newHistoryTestProducts(), newTestCategories(), newHistoryTestPriceLists(), testTaxRates))
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?"+query, nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+		handler.HandleGetByCode(w, req)
+		return w
+	}
+
+	t.Run("inherited variant price", func(t *testing.T) {
+		w := get("asOf=2026-01-15T00:00:00Z")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "10", resp.Price.Amount.String())
+		require.NotNil(t, resp.AsOf)
+		// The blue variant didn't exist yet, the red one inherited the product price
+		require.Len(t, resp.Variants, 1)
+		assert.Equal(t, "SKU001-R", resp.Variants[0].SKU)
+		assert.Equal(t, "10", resp.Variants[0].Price.Amount.String())
+	})
+
+	t.Run("own variant price", func(t *testing.T) {
+		w := get("asOf=2026-02-15T00:00:00Z&priceFormat=exact")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "10.00", resp.Price.Amount.StringFixed(2))
+		require.Len(t, resp.Variants, 1)
+		assert.Equal(t, "15.00", resp.Variants[0].Price.Amount.StringFixed(2))
+	})
+
+	t.Run("period boundary belongs to the new price", func(t *testing.T) {
+		w := get("asOf=2026-03-01T00:00:00Z")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "12", resp.Price.Amount.String())
+	})
+
+	t.Run("before the product existed", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("asOf=2025-06-01T00:00:00Z").Code)
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("asOf=yesterday").Code)
+	})
+
+	t.Run("other currency", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("asOf=2026-02-15T00:00:00Z&currency=USD").Code)
+	})
+}
+
+func TestHandlePriceHistory(t *testing.T) {
+	handler := NewCatalogHandler(NewCatalogService(newHistoryTestProducts(), newTestCategories(), newHistoryTestPriceLists(), testTaxRates))
+
+	req := httptest.NewRequest("GET", "/catalog/PROD001/price-history?priceFormat=exact", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandlePriceHistory(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"code": "PROD001",
+		"prices": [
+			{"price": {"amount": "10.00", "currency": "EUR"}, "valid_from": "2026-01-01T00:00:00Z", "valid_to": "2026-03-01T00:00:00Z"},
+			{"price": {"amount": "12.00", "currency": "EUR"}, "valid_from": "2026-03-01T00:00:00Z", "valid_to": null}
+		],
+		"variants": [
+			{"sku": "SKU001-R", "prices": [
+				{"price": null, "valid_from": "2026-01-01T00:00:00Z", "valid_to": "2026-02-01T00:00:00Z"},
+				{"price": {"amount": "15.00", "currency": "EUR"}, "valid_from": "2026-02-01T00:00:00Z", "valid_to": null}
+			]},
+			{"sku": "SKU001-G", "prices": [
+				{"price": {"amount": "9.00", "currency": "EUR"}, "valid_from": "2026-01-01T00:00:00Z", "valid_to": null}
+			]},
+			{"sku": "SKU001-B", "prices": [
+				{"price": null, "valid_from": "2026-04-01T00:00:00Z", "valid_to": null}
+			]}
+		]
+	}`, w.Body.String())
+
+	t.Run("unknown product", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/INVALID/price-history", nil)
+		req.SetPathValue("code", "INVALID")
+		w := httptest.NewRecorder()
+
+		handler.HandlePriceHistory(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// newPromotionTestPriceLists runs a category, a product and a sku promotion,
+// already in the order they take precedence like GetActivePromotions returns
+func newPromotionTestPriceLists() *mockPriceLists {
+	prices := newTestPriceLists()
+	prices.promotions = []models.Promotion{
+		{ID: 3, Type: models.PromotionFixedPrice, Value: decimal.RequireFromString("50"), Scope: models.PromotionScopeSKU, Target: "SKU001-R", Priority: 10, TargetIDs: []uint{1}},
+		{ID: 1, Type: models.PromotionPercentOff, Value: decimal.RequireFromString("20"), Scope: models.PromotionScopeCategory, Target: "CLOTHING", Priority: 5, TargetIDs: []uint{1, 2}},
+		{ID: 2, Type: models.PromotionFixedOff, Value: decimal.RequireFromString("15"), Scope: models.PromotionScopeProduct, Target: "PROD001", TargetIDs: []uint{1}},
+	}
+	return prices
+}
+
+func TestHandleGetByCode_Promotions(t *testing.T) {
+	categoryID := uint(2)
+	product := &models.Product{
+		ID:         1,
+		Code:       "PROD001",
+		Price:      decimal.RequireFromString("100"),
+		CategoryID: &categoryID,
+		Variants: []models.Variant{
+			{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("80")},
+			{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+		},
+	}
+
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return product, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newPromotionTestPriceLists(), testTaxRates))
+
+	t.Run("highest priority wins", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?priceFormat=exact", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"code": "PROD001",
+			"price": {"amount": "100.00", "currency": "EUR"},
+			"sale_price": {"amount": "80.00", "currency": "EUR"},
+			"promotion_ids": [1],
+			"variants": [
+				{"name": "Red", "sku": "SKU001-R", "price": {"amount": "80.00", "currency": "EUR"},
+				 "sale_price": {"amount": "50.00", "currency": "EUR"}, "promotion_ids": [3], "quantity": 0, "available": false},
+				{"name": "Blue", "sku": "SKU001-B", "price": {"amount": "100.00", "currency": "EUR"},
+				 "sale_price": {"amount": "80.00", "currency": "EUR"}, "promotion_ids": [1], "quantity": 0, "available": false}
+			]
+		}`, w.Body.String())
+	})
+
+	t.Run("fixed values are converted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?currency=GBP", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp ProductDetail
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "85", resp.Price.Amount.String())
+		assert.Equal(t, "68", resp.SalePrice.Amount.String())
+		require.Len(t, resp.Variants, 2)
+		assert.Equal(t, "68", resp.Variants[0].Price.Amount.String())
+		assert.Equal(t, "42.5", resp.Variants[0].SalePrice.Amount.String())
+	})
+
+	t.Run("products outside every scope keep their price", func(t *testing.T) {
+		other := &models.Product{ID: 5, Code: "PROD005", Price: decimal.RequireFromString("30")}
+		repo := &mockProductsRepo{
+			getByCodeFn: func(code string) (*models.Product, error) {
+				return other, nil
+			},
+		}
+		handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newPromotionTestPriceLists(), testTaxRates))
+
+		req := httptest.NewRequest("GET", "/catalog/PROD005", nil)
+		req.SetPathValue("code", "PROD005")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"code": "PROD005", "price": 30, "variants": []}`, w.Body.String())
+	})
+}
+
+func TestHandleGet_Promotions(t *testing.T) {
+	categoryID := uint(2)
+	var lastFilter models.ProductFilter
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			lastFilter = filter
+			return []models.Product{
+				{ID: 5, Code: "PROD005", Price: decimal.RequireFromString("30")},
+				{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("100"), CategoryID: &categoryID},
+			}, 3, nil
+		},
+	}
+	prices := newPromotionTestPriceLists()
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), prices, testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=2&sort=price&priceLessThan=90", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The repository filters and sorts by the same promotions
+	assert.Equal(t, prices.promotions, lastFilter.Promotions)
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Products, 2)
+	assert.Nil(t, resp.Products[0].SalePrice)
+	assert.Empty(t, resp.Products[0].PromotionIDs)
+	assert.Equal(t, "100", resp.Products[1].Price.Amount.String())
+	assert.Equal(t, "80", resp.Products[1].SalePrice.Amount.String())
+	assert.Equal(t, []uint{1}, resp.Products[1].PromotionIDs)
+
+	// The cursor continues after the sale price of the last product
+	require.NotEmpty(t, resp.NextCursor)
+	w = httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?limit=2&sort=price&priceLessThan=90&cursor="+resp.NextCursor, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, lastFilter.After, 2)
+	assert.True(t, decimal.RequireFromString("80").Equal(lastFilter.After[0].(decimal.Decimal)))
+}
+
+// newTaxTestCategories adds BOOKS with the reduced class and COMICS below it,
+// which inherits the class
+func newTaxTestCategories() *mockCategoriesFinder {
+	categories := newTestCategories()
+	reduced := models.TaxClassReduced
+	books := uint(3)
+	categories.categories["BOOKS"] = &models.Category{ID: books, Code: "BOOKS", Name: "Books", TaxClass: &reduced}
+	categories.categories["COMICS"] = &models.Category{ID: 4, Code: "COMICS", Name: "Comics", ParentID: &books}
+	return categories
+}
+
+func TestHandleGetByCode_Tax(t *testing.T) {
+	clothing, comics := uint(1), uint(4)
+	zero := models.TaxClassZero
+	products := map[string]*models.Product{
+		"PROD001": {ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"), CategoryID: &clothing},
+		"PROD002": {ID: 2, Code: "PROD002", Price: decimal.RequireFromString("10.99"), CategoryID: &comics},
+		"PROD003": {ID: 3, Code: "PROD003", Price: decimal.RequireFromString("10.99"), CategoryID: &comics, TaxClass: &zero},
+	}
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return products[code], nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTaxTestCategories(), newTestPriceLists(), testTaxRates))
+
+	tests := []struct {
+		name  string
+		code  string
+		class string
+		tax   string
+		gross string
+	}{
+		{"standard without a class", "PROD001", models.TaxClassStandard, "2.09", "13.08"},
+		{"inherited from the parent category", "PROD002", models.TaxClassReduced, "0.77", "11.76"},
+		{"the product's own class wins", "PROD003", models.TaxClassZero, "0", "10.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/catalog/"+tt.code+"?country=de", nil)
+			req.SetPathValue("code", tt.code)
+			w := httptest.NewRecorder()
+
+			handler.HandleGetByCode(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp ProductDetail
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Tax)
+			assert.Equal(t, "DE", resp.Tax.Country)
+			assert.Equal(t, tt.class, resp.Tax.Class)
+			assert.Equal(t, "10.99", resp.Tax.Net.Amount.String())
+			assert.Equal(t, tt.tax, resp.Tax.Tax.Amount.String())
+			assert.Equal(t, tt.gross, resp.Tax.Gross.Amount.String())
+			assert.True(t, resp.Tax.Net.Amount.Add(resp.Tax.Tax.Amount).Equal(resp.Tax.Gross.Amount))
+		})
+	}
+
+	t.Run("without a country", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"tax"`)
+	})
+
+	t.Run("unknown country", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/catalog/PROD001?country=XX", nil)
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleGetByCode_TaxOnSalePrice(t *testing.T) {
+	categoryID := uint(2)
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{
+				ID: 1, Code: "PROD001", Price: decimal.RequireFromString("100"), CategoryID: &categoryID,
+				Variants: []models.Variant{
+					{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("80")},
+				},
+			}, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newPromotionTestPriceLists(), testTaxRates))
+
+	req := httptest.NewRequest("GET", "/catalog/PROD001?country=CH&currency=GBP&priceFormat=exact", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetByCode(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	// The product sells at 20% off 85.00 GBP, 8.1% of 68.00 is 5.508
+	require.NotNil(t, resp.Tax)
+	assert.Equal(t, "GBP", resp.Tax.Gross.Currency)
+	assert.Equal(t, "68", resp.Tax.Net.Amount.String())
+	assert.Equal(t, "5.51", resp.Tax.Tax.Amount.String())
+	assert.Equal(t, "73.51", resp.Tax.Gross.Amount.String())
+
+	// The variant sells at the converted fixed price of 42.50
+	require.Len(t, resp.Variants, 1)
+	require.NotNil(t, resp.Variants[0].Tax)
+	assert.Equal(t, "42.5", resp.Variants[0].Tax.Net.Amount.String())
+	assert.Equal(t, "3.44", resp.Variants[0].Tax.Tax.Amount.String())
+	assert.Equal(t, "45.94", resp.Variants[0].Tax.Gross.Amount.String())
+}
+
+func TestHandleGet_Tax(t *testing.T) {
+	comics := uint(4)
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return []models.Product{
+				{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99")},
+				{ID: 2, Code: "PROD002", Price: decimal.RequireFromString("20"), CategoryID: &comics},
+			}, 2, nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTaxTestCategories(), newTestPriceLists(), testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?country=DE", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp PaginatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Products, 2)
+	require.NotNil(t, resp.Products[0].Tax)
+	assert.Equal(t, "13.08", resp.Products[0].Tax.Gross.Amount.String())
+	require.NotNil(t, resp.Products[1].Tax)
+	assert.Equal(t, models.TaxClassReduced, resp.Products[1].Tax.Class)
+	assert.Equal(t, "21.4", resp.Products[1].Tax.Gross.Amount.String())
+
+	w = httptest.NewRecorder()
+	handler.HandleGet(w, httptest.NewRequest("GET", "/catalog?country=XX", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleCreate_TaxClass(t *testing.T) {
+	var created *models.Product
+	repo := &mockProductsRepo{
+		createFn: func(product *models.Product) error {
+			created = product
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	w := httptest.NewRecorder()
+	handler.HandleCreate(w, httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(`{"code":"PROD100","price":"9.90","tax_class":"Reduced"}`)))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created.TaxClass)
+	assert.Equal(t, models.TaxClassReduced, *created.TaxClass)
+
+	var resp ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.TaxClassReduced, resp.TaxClass)
+
+	w = httptest.NewRecorder()
+	handler.HandleCreate(w, httptest.NewRequest("POST", "/catalog", bytes.NewBufferString(`{"code":"PROD101","price":"9.90","tax_class":"luxury"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandlePatch_TaxClass(t *testing.T) {
+	reduced := models.TaxClassReduced
+	var updated *models.Product
+	repo := &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			return &models.Product{ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"), TaxClass: &reduced}, nil
+		},
+		updateFn: func(product *models.Product) error {
+			updated = product
+			return nil
+		},
+	}
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/catalog/PROD001", bytes.NewBufferString(body))
+		req.SetPathValue("code", "PROD001")
+		w := httptest.NewRecorder()
+		handler.HandlePatch(w, req)
+		return w
+	}
+
+	// Leaving tax_class out keeps it, an empty one inherits again
+	require.Equal(t, http.StatusOK, patch(`{"brand":"Acme"}`).Code)
+	require.NotNil(t, updated.TaxClass)
+	assert.Equal(t, models.TaxClassReduced, *updated.TaxClass)
+
+	require.Equal(t, http.StatusOK, patch(`{"tax_class":""}`).Code)
+	assert.Nil(t, updated.TaxClass)
+
+	assert.Equal(t, http.StatusBadRequest, patch(`{"tax_class":"luxury"}`).Code)
+}
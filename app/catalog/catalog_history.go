@@ -0,0 +1,108 @@
+package catalog
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// GetPriceHistory lists the base prices a product and its variants had over
+// time. Variants deleted or purged since keep their history.
+func (s *CatalogService) GetPriceHistory(code string, opts ViewOptions) (*PriceHistoryResponse, error) {
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !prices.isBase() {
+		return nil, ErrHistoryCurrency
+	}
+
+	product, err := s.viewProduct(code, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	entries, err := s.prices.GetPriceHistory(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PriceHistoryResponse{
+		Code:     product.Code,
+		Prices:   []PricePeriod{},
+		Variants: []VariantPriceHistory{},
+	}
+
+	// Variants are listed by sku in the order their first price was recorded
+	variants := make(map[string]int)
+	for _, e := range entries {
+		period := PricePeriod{ValidFrom: e.ValidFrom, ValidTo: e.ValidTo}
+		if e.Amount.Valid {
+			price := prices.price(e.Amount.Decimal)
+			period.Price = &price
+		}
+
+		if e.SKU == nil {
+			response.Prices = append(response.Prices, period)
+			continue
+		}
+
+		i, ok := variants[*e.SKU]
+		if !ok {
+			i = len(response.Variants)
+			variants[*e.SKU] = i
+			response.Variants = append(response.Variants, VariantPriceHistory{SKU: *e.SKU})
+		}
+		response.Variants[i].Prices = append(response.Variants[i].Prices, period)
+	}
+
+	return response, nil
+}
+
+// applyPricesAt replaces the prices of a product and its variants with the
+// ones in effect at the given moment. Variants that had no price then didn't
+// exist yet and are dropped. The price inheritance of the detail mapping
+// applies to the historical prices as it does to the current ones.
+func (s *CatalogService) applyPricesAt(product *models.Product, at time.Time) error {
+	entries, err := s.prices.GetPricesAt(product.ID, at)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	variantPrices := make(map[uint]decimal.NullDecimal)
+	for _, e := range entries {
+		switch {
+		case e.SKU == nil:
+			product.Price = e.Amount.Decimal
+			found = true
+		case e.VariantID != nil:
+			variantPrices[*e.VariantID] = e.Amount
+		}
+	}
+	if !found {
+		return ErrNoPriceAtTime
+	}
+
+	variants := make([]models.Variant, 0, len(product.Variants))
+	for _, v := range product.Variants {
+		amount, ok := variantPrices[v.ID]
+		if !ok {
+			continue
+		}
+		// A zero price makes the variant inherit the product price
+		v.Price = decimal.Zero
+		if amount.Valid {
+			v.Price = amount.Decimal
+		}
+		variants = append(variants, v)
+	}
+	product.Variants = variants
+
+	return nil
+}
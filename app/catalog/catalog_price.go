@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// Price is a money amount in a response. By default it's written as a plain
+// JSON number to keep the original contract, with ViewOptions.ExactPrices it's
+// written as {"amount":"10.99","currency":"EUR"} so no precision is lost.
+// Prices are built by pricing, which knows the currency of the response.
+type Price struct {
+	Amount   decimal.Decimal
+	Currency string
+	Exact    bool
+}
+
+type exactPrice struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	if !p.Exact {
+		return json.Marshal(p.Amount.InexactFloat64())
+	}
+
+	return json.Marshal(exactPrice{
+		Amount:   p.Amount.StringFixed(2),
+		Currency: p.Currency,
+	})
+}
+
+// UnmarshalJSON accepts both representations, which keeps API clients written
+// in Go (and the tests) independent of the price format
+func (p *Price) UnmarshalJSON(data []byte) error {
+	var exact exactPrice
+	if err := json.Unmarshal(data, &exact); err == nil {
+		amount, err := decimal.NewFromString(exact.Amount)
+		if err != nil {
+			return err
+		}
+		*p = Price{Amount: amount, Currency: exact.Currency, Exact: true}
+		return nil
+	}
+
+	*p = Price{}
+	return json.Unmarshal(data, &p.Amount)
+}
@@ -0,0 +1,163 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// pricing resolves the prices of a response in the currency and market of
+// the request. Explicit price list entries win, a market specific entry over
+// one for the whole currency, and every other price is converted from the
+// base currency with the stored exchange rate. Running promotions are then
+// applied on top as sale prices, and taxes when the request has a country.
+type pricing struct {
+	currency   string
+	market     string
+	exact      bool
+	rate       decimal.Decimal
+	products   map[uint]decimal.Decimal
+	variants   map[uint]decimal.Decimal
+	promotions []models.Promotion
+	tax        *taxation
+}
+
+// newPricing validates the currency and market of opts. A market implies its
+// currency, sending both only works when they agree. Historical prices (AsOf)
+// are shown without promotions, only the base prices have a history.
+func newPricing(prices PriceLists, opts ViewOptions) (*pricing, error) {
+	p := &pricing{
+		currency: opts.Currency,
+		market:   opts.Market,
+		exact:    opts.ExactPrices,
+		rate:     decimal.NewFromInt(1),
+	}
+
+	if p.market != "" {
+		market, err := prices.GetMarketByCode(p.market)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownMarket, p.market)
+			}
+			return nil, err
+		}
+
+		if p.currency != "" && p.currency != market.Currency {
+			return nil, fmt.Errorf("%w: market %s sells in %s", ErrCurrencyMarketMismatch, market.Code, market.Currency)
+		}
+		p.currency = market.Currency
+	}
+
+	if p.currency == "" {
+		p.currency = models.BaseCurrency
+	}
+
+	if p.currency != models.BaseCurrency {
+		rate, err := prices.GetExchangeRate(p.currency)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, p.currency)
+			}
+			return nil, err
+		}
+		p.rate = rate
+	}
+
+	if opts.AsOf == nil {
+		promotions, err := prices.GetActivePromotions()
+		if err != nil {
+			return nil, err
+		}
+		p.promotions = promotions
+	}
+
+	return p, nil
+}
+
+// isBase reports whether the stored prices can be returned as they are
+func (p *pricing) isBase() bool {
+	return p.currency == models.BaseCurrency && p.market == ""
+}
+
+// load fetches the price list entries of the products and their variants
+func (p *pricing) load(prices PriceLists, products ...models.Product) error {
+	if p.isBase() || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	entries, err := prices.GetPriceListEntries(ids, p.currency, p.market)
+	if err != nil {
+		return err
+	}
+
+	p.products = make(map[uint]decimal.Decimal)
+	p.variants = make(map[uint]decimal.Decimal)
+
+	// Entries without a market come first, so market entries overwrite them
+	for _, e := range entries {
+		if e.VariantID != nil {
+			p.variants[*e.VariantID] = e.Amount
+		} else {
+			p.products[e.ProductID] = e.Amount
+		}
+	}
+
+	return nil
+}
+
+// product returns the price of a product
+func (p *pricing) product(product models.Product) Price {
+	if amount, ok := p.products[product.ID]; ok {
+		return p.price(amount)
+	}
+
+	return p.convert(product.Price)
+}
+
+// variant returns the price of a variant. Variants without an own price in
+// the currency inherit the already resolved price of their product.
+func (p *pricing) variant(v models.Variant, productPrice Price) Price {
+	if amount, ok := p.variants[v.ID]; ok {
+		return p.price(amount)
+	}
+
+	if !v.Price.IsZero() {
+		return p.convert(v.Price)
+	}
+
+	return productPrice
+}
+
+// convert turns a base currency amount into the response currency
+func (p *pricing) convert(amount decimal.Decimal) Price {
+	if p.currency == models.BaseCurrency {
+		return p.price(amount)
+	}
+
+	return p.price(amount.Mul(p.rate).Round(2))
+}
+
+// view is the currency and market of the prices for the price filters, the
+// price sort and the price facets, which resolve prices in SQL like here
+func (p *pricing) view() models.PriceView {
+	if p.isBase() {
+		return models.PriceView{}
+	}
+
+	return models.PriceView{Currency: p.currency, Market: p.market, Rate: p.rate}
+}
+
+func (p *pricing) price(amount decimal.Decimal) Price {
+	return Price{
+		Amount:   amount,
+		Currency: p.currency,
+		Exact:    p.exact,
+	}
+}
@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"slices"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Promotions are evaluated here for the responses and, through
+// ProductFilter.Promotions, in SQL for the price filters, the price sort and
+// the price facets. Both follow the same rules: the running promotions come
+// in the order they take precedence and a price gets the first one matching
+// it. Products match category and product promotions, variants also match
+// sku promotions.
+
+// productSale returns the sale price of a product and the promotions applied
+// to its resolved price, nil when none applies
+func (p *pricing) productSale(product models.Product, original Price) (*Price, []uint) {
+	return p.sale(p.promotionFor(product, nil), original)
+}
+
+// variantSale returns the sale price of a variant and the promotions applied
+// to its resolved (possibly inherited) price, nil when none applies
+func (p *pricing) variantSale(product models.Product, variant models.Variant, original Price) (*Price, []uint) {
+	return p.sale(p.promotionFor(product, &variant), original)
+}
+
+// selling is the price a product sells for in the response currency, its
+// sale price or its resolved one. It's the value the price sort and filters
+// compare, its price list entries have to be loaded.
+func (p *pricing) selling(product models.Product) decimal.Decimal {
+	price := p.product(product)
+	sale, _ := p.productSale(product, price)
+	return selling(price, sale).Amount
+}
+
+func (p *pricing) sale(promotion *models.Promotion, original Price) (*Price, []uint) {
+	if promotion == nil {
+		return nil, nil
+	}
+
+	// Fixed values are base currency amounts like the stored prices
+	sale := p.price(discount(*promotion, original.Amount, p.convert(promotion.Value).Amount))
+	return &sale, []uint{promotion.ID}
+}
+
+// promotionFor finds the promotion that applies to a product, or to one of
+// its variants when variant is set
+func (p *pricing) promotionFor(product models.Product, variant *models.Variant) *models.Promotion {
+	for i := range p.promotions {
+		promotion := &p.promotions[i]
+
+		var id uint
+		switch promotion.Scope {
+		case models.PromotionScopeCategory:
+			if product.CategoryID == nil {
+				continue
+			}
+			id = *product.CategoryID
+		case models.PromotionScopeProduct:
+			id = product.ID
+		case models.PromotionScopeSKU:
+			if variant == nil {
+				continue
+			}
+			id = variant.ID
+		default:
+			continue
+		}
+
+		if slices.Contains(promotion.TargetIDs, id) {
+			return promotion
+		}
+	}
+	return nil
+}
+
+// discount applies a promotion to an amount, fixed is the promotion value in
+// the currency of the amount. Percentages are rounded half away from zero to
+// cents like ROUND in Postgres, a discount never goes below zero and a fixed
+// price never raises a price.
+func discount(promotion models.Promotion, amount, fixed decimal.Decimal) decimal.Decimal {
+	switch promotion.Type {
+	case models.PromotionPercentOff:
+		return amount.Mul(hundred.Sub(promotion.Value)).Div(hundred).Round(2)
+	case models.PromotionFixedOff:
+		return decimal.Max(amount.Sub(fixed), decimal.Zero)
+	case models.PromotionFixedPrice:
+		return decimal.Min(amount, fixed)
+	}
+	return amount
+}
@@ -1,40 +1,710 @@
 package catalog
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// maxPrice is the first value that no longer fits the DECIMAL(10,2) columns
+var maxPrice = decimal.New(1, 8)
+
 type CatalogService struct {
-	repo ProductsReader
+	repo       ProductsStore
+	categories CategoriesFinder
+	prices     PriceLists
+	taxes      TaxRates
 }
 
-func NewCatalogService(repo ProductsReader) *CatalogService {
+func NewCatalogService(repo ProductsStore, categories CategoriesFinder, prices PriceLists, taxes TaxRates) *CatalogService {
 	return &CatalogService{
-		repo: repo,
+		repo:       repo,
+		categories: categories,
+		prices:     prices,
+		taxes:      taxes,
 	}
 }
 
-func (s *CatalogService) ListProducts(offset, limit int, category string, priceLessThan *float64) (*PaginatedResponse, error) {
-	products, total, err := s.repo.GetProductsWithPagination(offset, limit, category, priceLessThan)
+func (s *CatalogService) ListProducts(offset, limit int, filter models.ProductFilter, facets FacetOptions, opts ViewOptions) (*PaginatedResponse, error) {
+	prices, err := newPricing(s.prices, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if prices.tax, err = s.newTaxation(opts.Country); err != nil {
+		return nil, err
+	}
+
+	// Price filters are sent in the response currency. The bounds, the price
+	// sort and facets compare the prices the response shows: price list
+	// entries and converted base prices, with the promotions applied.
+	filter.Prices = prices.view()
+	filter.Promotions = prices.promotions
+
+	products, total, err := s.repo.GetProductsWithPagination(offset, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := prices.load(s.prices, products...); err != nil {
+		return nil, err
+	}
+
 	productDTOs := make([]Product, len(products))
 	for i, p := range products {
-		productDTOs[i] = mapProductToDTO(p)
+		productDTOs[i] = mapProductToDTO(p, prices, opts.Locales)
+		if filter.Search != "" {
+			productDTOs[i].Score = &p.SearchRank
+		}
 	}
 
-	return &PaginatedResponse{
+	response := &PaginatedResponse{
 		Products: productDTOs,
-		Total:    total,
 		Offset:   offset,
 		Limit:    limit,
+		Sort:     formatSort(filter.Sort),
+	}
+
+	if !filter.SkipTotal {
+		response.Total = &total
+	}
+
+	// A full page may have more products after it. In offset mode the total
+	// tells for sure, in keyset mode the last page can come back empty.
+	hasMore := len(products) == limit
+	if hasMore && !filter.SkipTotal && len(filter.After) == 0 {
+		hasMore = int64(offset+limit) < total
+	}
+	if hasMore {
+		response.NextCursor = encodeCursor(filter.Sort, products[len(products)-1], prices)
+	}
+
+	if response.Facets, err = s.listFacets(filter, facets, prices); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// listFacets counts the products of the whole filtered result, not only the
+// current page, so paging and cursors don't change the facets. Price buckets
+// are computed on the prices of the response currency like the price filters.
+func (s *CatalogService) listFacets(filter models.ProductFilter, options FacetOptions, prices *pricing) (*Facets, error) {
+	if !options.Categories && !options.Price {
+		return nil, nil
+	}
+
+	facets := &Facets{}
+
+	if options.Categories {
+		counts, err := s.repo.GetCategoryFacets(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		facets.Categories = make([]CategoryFacet, len(counts))
+		for i, c := range counts {
+			facets.Categories[i] = CategoryFacet{Code: c.Code, Name: c.Name, Count: c.Count}
+		}
+	}
+
+	if options.Price {
+		buckets, err := s.repo.GetPriceFacets(filter, options.PriceBucketSize)
+		if err != nil {
+			return nil, err
+		}
+
+		facets.Price = make([]PriceFacet, len(buckets))
+		for i, b := range buckets {
+			facets.Price[i] = PriceFacet{Min: prices.price(b.Min), Max: prices.price(b.Max), Count: b.Count}
+		}
+	}
+
+	return facets, nil
+}
+
+func (s *CatalogService) GetProductDetails(code string, opts ViewOptions) (*ProductDetail, error) {
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.AsOf != nil && !prices.isBase() {
+		return nil, ErrHistoryCurrency
+	}
+
+	if prices.tax, err = s.newTaxation(opts.Country); err != nil {
+		return nil, err
+	}
+
+	product, err := s.viewProduct(code, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	if opts.AsOf == nil {
+		return s.mapProductDetail(product, prices, opts.Locales)
+	}
+
+	if err := s.applyPricesAt(product, *opts.AsOf); err != nil {
+		return nil, err
+	}
+
+	detail, err := s.mapProductDetail(product, prices, opts.Locales)
+	if err != nil {
+		return nil, err
+	}
+	detail.AsOf = opts.AsOf
+	return detail, nil
+}
+
+// viewProduct loads a product for a read request, outside the admin routes
+// only published products are found
+func (s *CatalogService) viewProduct(code string, opts ViewOptions) (*models.Product, error) {
+	code = strings.ToUpper(code)
+	if opts.IncludeUnpublished {
+		return s.repo.GetProductByCode(code)
+	}
+	return s.repo.GetPublishedProductByCode(code)
+}
+
+func (s *CatalogService) CreateProduct(actor models.Actor, req CreateProductRequest, opts ViewOptions) (*ProductDetail, error) {
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &models.Product{
+		Code:         strings.ToUpper(req.Code),
+		Price:        req.Price,
+		Brand:        strings.TrimSpace(req.Brand),
+		Status:       models.ProductStatusDraft,
+		PublishFrom:  req.PublishFrom,
+		PublishUntil: req.PublishUntil,
+	}
+	if req.Status != "" {
+		product.Status = strings.ToLower(req.Status)
+	}
+	product.TaxClass = parseTaxClass(req.TaxClass)
+
+	if req.Category != "" {
+		category, err := s.findCategory(req.Category)
+		if err != nil {
+			return nil, err
+		}
+		product.CategoryID = &category.ID
+		product.Category = category
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionCreate, models.AuditEntityProduct, product.Code)
+	entry.After = productSnapshot(product)
+
+	if err := s.repo.CreateProduct(product, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrProductAlreadyExists
+		case errors.Is(err, models.ErrCategoryDeleted):
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return s.mapProductDetail(product, prices, opts.Locales)
+}
+
+// UpdateProduct replaces the price, category and publishing window of an
+// existing product
+func (s *CatalogService) UpdateProduct(actor models.Actor, code string, req UpdateProductRequest, opts ViewOptions) (*ProductDetail, error) {
+	if req.Price == nil {
+		return nil, ErrProductPriceInvalid
+	}
+	if req.Category == nil {
+		req.Category = new(string)
+	}
+	if req.Brand == nil {
+		req.Brand = new(string)
+	}
+	if req.PublishFrom == nil {
+		req.PublishFrom = new(string)
+	}
+	if req.PublishUntil == nil {
+		req.PublishUntil = new(string)
+	}
+	if req.TaxClass == nil {
+		req.TaxClass = new(string)
+	}
+
+	return s.PatchProduct(actor, code, req, opts)
+}
+
+// PatchProduct only changes the fields present in the request
+func (s *CatalogService) PatchProduct(actor models.Actor, code string, req UpdateProductRequest, opts ViewOptions) (*ProductDetail, error) {
+	if req.Price != nil {
+		if err := validatePrice(*req.Price); err != nil {
+			return nil, err
+		}
+	}
+	if req.Brand != nil && len(strings.TrimSpace(*req.Brand)) > 128 {
+		return nil, ErrProductBrandTooLong
+	}
+	if req.Status != nil && !validStatus(*req.Status) {
+		return nil, ErrProductStatusInvalid
+	}
+	if req.TaxClass != nil && !validTaxClass(*req.TaxClass) {
+		return nil, ErrTaxClassInvalid
+	}
+
+	publishFrom, err := parsePublishTime(req.PublishFrom)
+	if err != nil {
+		return nil, err
+	}
+	publishUntil, err := parsePublishTime(req.PublishUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionUpdate, models.AuditEntityProduct, product.Code)
+	entry.Before = productSnapshot(product)
+
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Brand != nil {
+		product.Brand = strings.TrimSpace(*req.Brand)
+	}
+	if req.Status != nil {
+		product.Status = strings.ToLower(*req.Status)
+	}
+	if req.PublishFrom != nil {
+		product.PublishFrom = publishFrom
+	}
+	if req.PublishUntil != nil {
+		product.PublishUntil = publishUntil
+	}
+	if req.TaxClass != nil {
+		product.TaxClass = parseTaxClass(*req.TaxClass)
+	}
+
+	// The window is checked after merging, a PATCH may only move one side
+	if err := validatePublishWindow(product.PublishFrom, product.PublishUntil); err != nil {
+		return nil, err
+	}
+
+	if req.Category != nil {
+		product.CategoryID = nil
+		product.Category = nil
+
+		if *req.Category != "" {
+			category, err := s.findCategory(*req.Category)
+			if err != nil {
+				return nil, err
+			}
+			product.CategoryID = &category.ID
+			product.Category = category
+		}
+	}
+
+	entry.After = productSnapshot(product)
+
+	if err := s.repo.UpdateProduct(product, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrProductNotFound
+		case errors.Is(err, models.ErrCategoryDeleted):
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return s.mapProductDetail(product, prices, opts.Locales)
+}
+
+// ReplaceOptions replaces the option axes of a product. Options and values
+// that are kept by name stay assigned to the variants using them.
+func (s *CatalogService) ReplaceOptions(actor models.Actor, code string, req ProductOptions) (*ProductOptions, error) {
+	if err := validateOptions(req.Options); err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]models.ProductOption, len(req.Options))
+	for i, o := range req.Options {
+		options[i].Name = strings.TrimSpace(o.Name)
+		for _, v := range o.Values {
+			options[i].Values = append(options[i].Values, models.ProductOptionValue{Value: strings.TrimSpace(v)})
+		}
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionReplaceOptions, models.AuditEntityProduct, product.Code)
+	entry.Before = optionsSnapshot(product.Options)
+	entry.After = optionsSnapshot(options)
+
+	if err := s.repo.ReplaceProductOptions(product.ID, options, entry); err != nil {
+		if errors.Is(err, models.ErrReferenced) {
+			return nil, ErrOptionInUse
+		}
+		return nil, err
+	}
+
+	return &ProductOptions{Options: mapOptionsToDTO(options)}, nil
+}
+
+// SaveTranslation creates or replaces the texts of a product in one locale
+func (s *CatalogService) SaveTranslation(actor models.Actor, code, locale string, req TranslationRequest) (*TranslationResponse, error) {
+	locale, ok := utils.NormalizeLocale(locale)
+	if !ok {
+		return nil, ErrInvalidLocale
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, ErrTitleRequired
+	}
+	if len(title) > 256 {
+		return nil, ErrTitleTooLong
+	}
+
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &models.ProductTranslation{
+		ProductID:   product.ID,
+		Locale:      locale,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionSaveTranslation, models.AuditEntityProduct, product.Code)
+	if existing := productTranslation(product, locale); existing != nil {
+		entry.Before = translationSnapshot(existing)
+	}
+	entry.After = translationSnapshot(translation)
+
+	if err := s.repo.SaveProductTranslation(translation, entry); err != nil {
+		return nil, err
+	}
+
+	return &TranslationResponse{
+		Locale:      translation.Locale,
+		Title:       translation.Title,
+		Description: translation.Description,
 	}, nil
 }
 
+func (s *CatalogService) DeleteTranslation(actor models.Actor, code, locale string) error {
+	locale, ok := utils.NormalizeLocale(locale)
+	if !ok {
+		return ErrInvalidLocale
+	}
+
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionDeleteTranslation, models.AuditEntityProduct, product.Code)
+	if existing := productTranslation(product, locale); existing != nil {
+		entry.Before = translationSnapshot(existing)
+	}
+
+	if err := s.repo.DeleteProductTranslation(product.ID, locale, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrTranslationNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteProduct soft-deletes a product together with its variants
+func (s *CatalogService) DeleteProduct(actor models.Actor, code string) error {
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return err
+	}
 
-func (s *CatalogService) GetProductDetails(code string) (*ProductDetail, error) {
-	product, err := s.repo.GetProductByCode(code)
+	entry := models.NewAuditEntry(actor, models.AuditActionDelete, models.AuditEntityProduct, product.Code)
+	entry.Before = productSnapshot(product)
+
+	if err := s.repo.DeleteProduct(product.Code, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RestoreProduct brings back a deleted product with the variants deleted
+// together with it. Its status is kept, so it's public again if it was before.
+// A product whose category is deleted can't be restored before the category.
+func (s *CatalogService) RestoreProduct(actor models.Actor, code string, opts ViewOptions) (*ProductDetail, error) {
+	code = strings.ToUpper(code)
+
+	prices, err := newPricing(s.prices, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return mapProductToDetailDTO(product), nil
+	entry := models.NewAuditEntry(actor, models.AuditActionRestore, models.AuditEntityProduct, code)
+	entry.Before = deletedSnapshot(true)
+	entry.After = deletedSnapshot(false)
+
+	if err := s.repo.RestoreProduct(code, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrProductNotFound
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrProductAlreadyExists
+		case errors.Is(err, models.ErrCategoryDeleted):
+			return nil, ErrCategoryDeleted
+		}
+		return nil, err
+	}
+
+	product, err := getProduct(s.repo, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapProductDetail(product, prices, opts.Locales)
+}
+
+// mapProductDetail prices a product and its variants in the response currency
+// and picks its texts from the locale chain
+func (s *CatalogService) mapProductDetail(product *models.Product, prices *pricing, locales []string) (*ProductDetail, error) {
+	if err := prices.load(s.prices, *product); err != nil {
+		return nil, err
+	}
+
+	return mapProductToDetailDTO(product, prices, locales), nil
+}
+
+// parseSort reads a comma separated list of sort keys, a leading "-" sorts
+// descending (e.g. "-price,code"). Without keys products are sorted by
+// relevance when searching and by id otherwise. The id tie-breaker is always
+// appended so offset pages don't shift between requests.
+func parseSort(raw string, searching bool) ([]models.SortField, error) {
+	var fields []models.SortField
+	hasID := false
+
+	for key := range strings.SplitSeq(raw, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		field := models.SortField{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+		if _, ok := models.ProductSortColumns[field.Field]; !ok || (field.Field == "relevance" && !searching) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, field.Field)
+		}
+
+		hasID = hasID || field.Field == "id"
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 && searching {
+		fields = append(fields, models.SortField{Field: "relevance", Desc: true})
+	}
+	if !hasID {
+		fields = append(fields, models.SortField{Field: "id"})
+	}
+
+	return fields, nil
+}
+
+// formatSort renders sort fields back into the sort query parameter format
+func formatSort(fields []models.SortField) string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Field
+		if f.Desc {
+			keys[i] = "-" + f.Field
+		}
+	}
+
+	return strings.Join(keys, ",")
+}
+
+// getProduct loads a product and maps a missing row to ErrProductNotFound.
+// Codes are stored upper case, so the path code is matched case-insensitively.
+func getProduct(products ProductsReader, code string) (*models.Product, error) {
+	product, err := products.GetProductByCode(strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *CatalogService) findCategory(code string) (*models.Category, error) {
+	category, err := s.categories.GetCategoryByCode(strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CatalogService) validateCreateRequest(req CreateProductRequest) error {
+	if strings.TrimSpace(req.Code) == "" {
+		return ErrProductCodeRequired
+	}
+
+	if len(req.Code) > 32 {
+		return ErrProductCodeTooLong
+	}
+
+	if len(strings.TrimSpace(req.Brand)) > 128 {
+		return ErrProductBrandTooLong
+	}
+
+	if req.Status != "" && !validStatus(req.Status) {
+		return ErrProductStatusInvalid
+	}
+
+	if !validTaxClass(req.TaxClass) {
+		return ErrTaxClassInvalid
+	}
+
+	if err := validatePublishWindow(req.PublishFrom, req.PublishUntil); err != nil {
+		return err
+	}
+
+	return validatePrice(req.Price)
+}
+
+func validStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case models.ProductStatusDraft, models.ProductStatusActive, models.ProductStatusArchived:
+		return true
+	}
+	return false
+}
+
+// validTaxClass accepts one of the models.TaxClasses, or empty to inherit
+// the class of the category
+func validTaxClass(class string) bool {
+	return class == "" || slices.Contains(models.TaxClasses, strings.ToLower(class))
+}
+
+// parseTaxClass stores an empty tax class as nil, which inherits
+func parseTaxClass(class string) *string {
+	if class == "" {
+		return nil
+	}
+	class = strings.ToLower(class)
+	return &class
+}
+
+// parsePublishTime reads an RFC 3339 publish time of a PATCH request, an
+// empty string clears it
+func parsePublishTime(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(*value))
+	if err != nil {
+		return nil, ErrPublishTimeInvalid
+	}
+	return &t, nil
+}
+
+func validatePublishWindow(from, until *time.Time) error {
+	if from != nil && until != nil && !until.After(*from) {
+		return ErrPublishWindowInvalid
+	}
+	return nil
+}
+
+// validateOptions checks that option names, and the values of each option,
+// are present and unique regardless of case
+func validateOptions(options []OptionDetail) error {
+	names := make(map[string]bool)
+
+	for _, o := range options {
+		name := strings.ToLower(strings.TrimSpace(o.Name))
+		if name == "" {
+			return ErrOptionNameRequired
+		}
+		if len(name) > 64 {
+			return ErrOptionTooLong
+		}
+		if names[name] {
+			return fmt.Errorf("%w: %s", ErrOptionDuplicate, o.Name)
+		}
+		names[name] = true
+
+		if len(o.Values) == 0 {
+			return fmt.Errorf("%w: %s", ErrOptionValuesRequired, o.Name)
+		}
+
+		values := make(map[string]bool)
+		for _, v := range o.Values {
+			value := strings.ToLower(strings.TrimSpace(v))
+			if value == "" {
+				return fmt.Errorf("%w: %s", ErrOptionValuesRequired, o.Name)
+			}
+			if len(value) > 64 {
+				return ErrOptionTooLong
+			}
+			if values[value] {
+				return fmt.Errorf("%w: %s", ErrOptionDuplicate, v)
+			}
+			values[value] = true
+		}
+	}
+
+	return nil
+}
+
+// validatePrice checks that price is positive and fits a DECIMAL(10,2) column
+func validatePrice(price decimal.Decimal) error {
+	if !price.IsPositive() {
+		return ErrProductPriceInvalid
+	}
+
+	if price.GreaterThanOrEqual(maxPrice) || !price.Equal(price.Round(2)) {
+		return ErrProductPricePrecision
+	}
+
+	return nil
 }
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// Catalog prices are net amounts. With ?country= every product and variant
+// also shows its selling price (the sale price when a promotion applies)
+// split into net, tax and gross for that country:
+//
+//	tax   = net * rate / 100, rounded to cents half away from zero
+//	gross = net + tax
+//
+// so net and tax always add up to gross exactly. Taxes are computed per unit
+// on the price in the response currency, after conversion and promotions.
+// Price filters, the price sort and facets keep working on net prices.
+
+// TaxRates maps country codes to the VAT percentage of every tax class
+type TaxRates map[string]map[string]decimal.Decimal
+
+// LoadTaxRates reads the rate table from a JSON file of the form
+// {"DE": {"standard": "19", "reduced": "7", "zero": "0"}}. Every country has
+// to configure a rate for each of the models.TaxClasses.
+func LoadTaxRates(path string) (TaxRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates TaxRates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("tax rates %s: %w", path, err)
+	}
+
+	if err := rates.validate(); err != nil {
+		return nil, fmt.Errorf("tax rates %s: %w", path, err)
+	}
+	return rates, nil
+}
+
+func (t TaxRates) validate() error {
+	for country, classes := range t {
+		if len(country) != 2 || country != strings.ToUpper(country) {
+			return fmt.Errorf("country %q must be an upper case ISO 3166 code", country)
+		}
+
+		for class, rate := range classes {
+			if !slices.Contains(models.TaxClasses, class) {
+				return fmt.Errorf("%s: unknown tax class %q", country, class)
+			}
+			if rate.IsNegative() || rate.GreaterThanOrEqual(hundred) || !rate.Equal(rate.Round(2)) {
+				return fmt.Errorf("%s: %s rate must be a percentage from 0 to below 100 with at most 2 decimal places", country, class)
+			}
+		}
+
+		for _, class := range models.TaxClasses {
+			if _, ok := classes[class]; !ok {
+				return fmt.Errorf("%s: no rate for tax class %s", country, class)
+			}
+		}
+	}
+	return nil
+}
+
+// taxation computes the taxes of one country for a response
+type taxation struct {
+	country string
+	rates   map[string]decimal.Decimal
+	// categories maps category ids to their tax class, already inherited
+	// from the ancestors where unset
+	categories map[uint]string
+}
+
+// newTaxation prepares the taxes of a country, no country means no taxes
+func (s *CatalogService) newTaxation(country string) (*taxation, error) {
+	if country == "" {
+		return nil, nil
+	}
+
+	rates, ok := s.taxes[country]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCountry, country)
+	}
+
+	categories, err := s.categories.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	return &taxation{
+		country:    country,
+		rates:      rates,
+		categories: categoryTaxClasses(categories),
+	}, nil
+}
+
+// categoryTaxClasses resolves the tax class of every category. A category
+// without one takes the class of its nearest ancestor that has one.
+func categoryTaxClasses(categories []models.Category) map[uint]string {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	classes := make(map[uint]string)
+	for _, c := range categories {
+		current := c
+		// seen stops at cycles, the data shouldn't have any
+		seen := map[uint]bool{}
+		for current.TaxClass == nil && current.ParentID != nil && !seen[current.ID] {
+			seen[current.ID] = true
+			parent, ok := byID[*current.ParentID]
+			if !ok {
+				break
+			}
+			current = parent
+		}
+
+		if current.TaxClass != nil {
+			classes[c.ID] = *current.TaxClass
+		}
+	}
+	return classes
+}
+
+// class returns the tax class of a product, the product's own one wins over
+// the one of its category
+func (t *taxation) class(product models.Product) string {
+	if product.TaxClass != nil {
+		return *product.TaxClass
+	}
+	if product.CategoryID != nil {
+		if class, ok := t.categories[*product.CategoryID]; ok {
+			return class
+		}
+	}
+	return models.TaxClassStandard
+}
+
+// taxed splits the selling price of a product, or of one of its variants,
+// into net, tax and gross. It's nil when the request asked for no country.
+func (p *pricing) taxed(product models.Product, net Price) *TaxedPrice {
+	if p.tax == nil {
+		return nil
+	}
+
+	class := p.tax.class(product)
+	rate := p.tax.rates[class]
+	tax := net.Amount.Mul(rate).Div(hundred).Round(2)
+
+	return &TaxedPrice{
+		Country: p.tax.country,
+		Class:   class,
+		Rate:    rate,
+		Net:     net,
+		Tax:     p.price(tax),
+		Gross:   p.price(net.Amount.Add(tax)),
+	}
+}
+
+// selling is the price a product or variant is sold for
+func selling(price Price, sale *Price) Price {
+	if sale != nil {
+		return *sale
+	}
+	return price
+}
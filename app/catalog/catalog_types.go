@@ -1,13 +1,172 @@
 package catalog
 
-import "github.com/mytheresa/go-hiring-challenge/models"
+import (
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrProductCodeRequired   = errors.New("product code is required")
+	ErrProductCodeTooLong    = errors.New("product code must not exceed 32 characters")
+	ErrProductPriceInvalid   = errors.New("product price must be a positive decimal")
+	ErrProductPricePrecision = errors.New("product price must have at most 8 integer digits and 2 decimal places")
+	ErrProductNotFound       = errors.New("product not found")
+	ErrProductAlreadyExists  = errors.New("product code already exists")
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryDeleted       = errors.New("the category of the product is deleted, restore it first")
+	ErrInvalidSort           = errors.New("invalid sort field")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidPriceFilter    = errors.New("invalid price filter")
+	ErrInvalidFacet          = errors.New("invalid facet")
+	ErrInvalidPriceFormat    = errors.New("invalid price format, use number or exact")
+
+	ErrUnknownCurrency        = errors.New("unknown currency")
+	ErrUnknownMarket          = errors.New("unknown market")
+	ErrCurrencyMarketMismatch = errors.New("currency does not match the market")
+	ErrUnknownCountry         = errors.New("unknown country, no tax rates are configured for it")
+	ErrTaxClassInvalid        = errors.New("tax class must be standard, reduced or zero")
+
+	ErrVariantNameRequired  = errors.New("variant name is required")
+	ErrVariantNameTooLong   = errors.New("variant name must not exceed 256 characters")
+	ErrVariantSKURequired   = errors.New("variant sku is required")
+	ErrVariantSKUTooLong    = errors.New("variant sku must not exceed 32 characters")
+	ErrVariantPriceInvalid  = errors.New("variant price must be a positive decimal with at most 8 integer digits and 2 decimal places")
+	ErrVariantNotFound      = errors.New("variant not found")
+	ErrVariantAlreadyExists = errors.New("variant sku already exists")
+
+	ErrStockDeltaInvalid = errors.New("stock delta must be a non-zero integer")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	ErrOptionNameRequired     = errors.New("option name is required")
+	ErrOptionValuesRequired   = errors.New("option needs at least one value")
+	ErrOptionTooLong          = errors.New("option names and values must not exceed 64 characters")
+	ErrOptionDuplicate        = errors.New("option names and the values of an option must be unique")
+	ErrOptionInUse            = errors.New("option or value is still used by a variant")
+	ErrInvalidAttribute       = errors.New("attribute is not an option value of the product")
+	ErrInvalidAttributeFilter = errors.New("invalid attribute filter")
+
+	ErrInvalidLocale       = errors.New("invalid locale, use a BCP 47 tag like en or de-CH")
+	ErrProductBrandTooLong = errors.New("product brand must not exceed 128 characters")
+	ErrTitleRequired       = errors.New("title is required")
+	ErrTitleTooLong        = errors.New("title must not exceed 256 characters")
+	ErrTranslationNotFound = errors.New("translation not found")
+
+	ErrProductStatusInvalid = errors.New("product status must be draft, active or archived")
+	ErrPublishTimeInvalid   = errors.New("publish times must be RFC 3339 timestamps")
+	ErrPublishWindowInvalid = errors.New("publish_until must be after publish_from")
+
+	ErrMediaNotFound          = errors.New("media not found")
+	ErrMediaIDInvalid         = errors.New("media id must be a positive integer")
+	ErrMediaURLRequired       = errors.New("media url is required")
+	ErrMediaURLInvalid        = errors.New("media url must be an absolute http or https url of at most 2048 characters")
+	ErrMediaAltTextTooLong    = errors.New("media alt text must not exceed 256 characters")
+	ErrMediaDimensionsInvalid = errors.New("media width and height must not be negative")
+	ErrMediaPositionInvalid   = errors.New("media position must not be negative")
+	ErrMediaVariantNotFound   = errors.New("media variant is not a variant of the product")
+
+	ErrAsOfInvalid     = errors.New("asOf must be an RFC 3339 timestamp")
+	ErrHistoryCurrency = errors.New("the price history only has base currency prices, asOf and price-history take no currency or market")
+	ErrNoPriceAtTime   = errors.New("product had no price at that time")
+
+	ErrQuoteItemsRequired   = errors.New("at least one item is required")
+	ErrQuoteTooManyItems    = errors.New("a quote takes at most 100 items")
+	ErrQuoteSKURequired     = errors.New("item sku is required")
+	ErrQuoteQuantityInvalid = errors.New("item quantity must be a positive integer of at most 10000, repeated skus added up")
+
+	ErrImportFormat        = errors.New("import format must be csv or ndjson")
+	ErrImportHeader        = errors.New("invalid csv header")
+	ErrImportTooLarge      = errors.New("import body must not exceed 32 MiB, use the import command for bigger files")
+	ErrImportRowInvalid    = errors.New("invalid row")
+	ErrImportPriceRequired = errors.New("price is required for a new product")
+	ErrImportVariantNoSKU  = errors.New("variant_name and variant_price need a sku")
+	ErrImportSKUTaken      = errors.New("sku belongs to another product")
+
+	ErrExportFormat = errors.New("export format must be csv or ndjson")
+)
 
 // ProductsReader interface for fetching products
 // This interface allows the handler to depend on behavior rather than concrete implementation
 type ProductsReader interface {
 	GetAllProducts() ([]models.Product, error)
-	GetProductsWithPagination(offset, limit int, category string, priceLessThan *float64) ([]models.Product, int64, error)
+	GetProductsWithPagination(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error)
 	GetProductByCode(code string) (*models.Product, error)
+	GetPublishedProductByCode(code string) (*models.Product, error)
+	GetPublishedProductsBySKUs(skus []string) ([]models.Product, error)
+	GetCategoryFacets(filter models.ProductFilter) ([]models.CategoryCount, error)
+	GetPriceFacets(filter models.ProductFilter, bucketSize decimal.Decimal) ([]models.PriceBucket, error)
+}
+
+// ProductsWriter interface for persisting product changes, each write
+// records its audit entry in the same transaction
+type ProductsWriter interface {
+	CreateProduct(product *models.Product, entry *models.AuditEntry) error
+	UpdateProduct(product *models.Product, entry *models.AuditEntry) error
+	DeleteProduct(code string, entry *models.AuditEntry) error
+	RestoreProduct(code string, entry *models.AuditEntry) error
+	ReplaceProductOptions(productID uint, options []models.ProductOption, entry *models.AuditEntry) error
+	SaveProductTranslation(translation *models.ProductTranslation, entry *models.AuditEntry) error
+	DeleteProductTranslation(productID uint, locale string, entry *models.AuditEntry) error
+}
+
+// ProductsStore combines read and write access to products
+type ProductsStore interface {
+	ProductsReader
+	ProductsWriter
+}
+
+// StockStore interface for reading and atomically adjusting variant stock
+type StockStore interface {
+	GetStockLevels(variantID uint) ([]models.StockLevel, error)
+	AdjustStock(variantID uint, warehouse string, delta int, entry *models.AuditEntry) (int, error)
+}
+
+// MediaStore interface for the image references of a product
+type MediaStore interface {
+	GetProductMedia(productID uint) ([]models.ProductMedia, error)
+	GetMedia(productID, id uint) (*models.ProductMedia, error)
+	CreateMedia(media *models.ProductMedia, entry *models.AuditEntry) error
+	UpdateMedia(media *models.ProductMedia, entry *models.AuditEntry) error
+	DeleteMedia(productID, id uint, entry *models.AuditEntry) error
+}
+
+// PriceLists interface for the markets, exchange rates and price list
+// entries used to price products in other currencies, the promotions giving
+// sale prices, and for the history of the base prices
+type PriceLists interface {
+	GetMarketByCode(code string) (*models.Market, error)
+	GetExchangeRate(currency string) (decimal.Decimal, error)
+	GetPriceListEntries(productIDs []uint, currency, market string) ([]models.PriceListEntry, error)
+	GetPriceHistory(productID uint) ([]models.PriceHistoryEntry, error)
+	GetPricesAt(productID uint, at time.Time) ([]models.PriceHistoryEntry, error)
+	GetActivePromotions() ([]models.Promotion, error)
+}
+
+// VariantsStore interface for reading and persisting the variants of a product
+type VariantsStore interface {
+	GetVariantsByProductID(productID uint) ([]models.Variant, error)
+	GetVariantBySKU(sku string) (*models.Variant, error)
+	CreateVariant(variant *models.Variant, entry *models.AuditEntry) error
+	UpdateVariant(variant *models.Variant, entry *models.AuditEntry) error
+	DeleteVariant(id uint, entry *models.AuditEntry) error
+	RestoreVariant(productID uint, sku string, entry *models.AuditEntry) error
+}
+
+// ImportStore loads the products an import batch touches and upserts them
+// with their audit entries in one transaction
+type ImportStore interface {
+	GetProductsForImport(codes, skus []string) ([]models.Product, error)
+	ImportProducts(products []models.Product, entries []*models.AuditEntry) error
+}
+
+// CategoriesFinder resolves the category code a product is assigned to, and
+// the category tree the tax classes are inherited through
+type CategoriesFinder interface {
+	GetCategoryByCode(code string) (*models.Category, error)
+	GetAllCategories() ([]models.Category, error)
 }
 
 type Response struct {
@@ -16,15 +175,84 @@ type Response struct {
 
 type PaginatedResponse struct {
 	Products []Product `json:"products"`
-	Total    int64     `json:"total"`
-	Offset   int       `json:"offset"`
-	Limit    int       `json:"limit"`
+	// Total is left out when the request sets includeTotal=false
+	Total  *int64 `json:"total,omitempty"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Sort   string `json:"sort"`
+	// NextCursor continues after the last product of this page and can be
+	// sent back as the cursor parameter instead of an offset
+	NextCursor string  `json:"next_cursor,omitempty"`
+	Facets     *Facets `json:"facets,omitempty"`
+}
+
+// ViewOptions controls how products are presented in responses
+type ViewOptions struct {
+	// ExactPrices writes prices as decimal strings with their currency
+	// instead of JSON numbers
+	ExactPrices bool
+	// Currency and Market select the price list, both empty means the
+	// stored base currency prices
+	Currency string
+	Market   string
+	// Locales is the fallback chain the texts are looked up in
+	Locales []string
+	// IncludeUnpublished also finds products outside the public catalog, it's
+	// only set by the admin routes
+	IncludeUnpublished bool
+	// Country adds the taxes of that country to the listing and product
+	// details
+	Country string
+	// AsOf prices a product detail with the base prices in effect at that
+	// moment instead of the current ones, promotions don't apply then
+	AsOf *time.Time
+}
+
+// FacetOptions selects the facets computed next to a product listing
+type FacetOptions struct {
+	Categories      bool
+	Price           bool
+	PriceBucketSize decimal.Decimal
+}
+
+type Facets struct {
+	Categories []CategoryFacet `json:"categories,omitempty"`
+	Price      []PriceFacet    `json:"price,omitempty"`
+}
+
+type CategoryFacet struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceFacet counts the products priced from Min (inclusive) to Max (exclusive)
+type PriceFacet struct {
+	Min   Price `json:"min"`
+	Max   Price `json:"max"`
+	Count int64 `json:"count"`
 }
 
 type Product struct {
-	Code     string    `json:"code"`
-	Price    float64   `json:"price"`
-	Category *Category `json:"category,omitempty"`
+	Code  string `json:"code"`
+	Title string `json:"title,omitempty"`
+	Brand string `json:"brand,omitempty"`
+	// Locale is the locale the title was found in, empty without a title
+	Locale string `json:"locale,omitempty"`
+	Price  Price  `json:"price"`
+	// SalePrice is the price after the promotions in PromotionIDs, both are
+	// only set when a promotion applies
+	SalePrice    *Price `json:"sale_price,omitempty"`
+	PromotionIDs []uint `json:"promotion_ids,omitempty"`
+	// Tax splits the selling price into net, tax and gross, only set when
+	// a country was asked for
+	Tax      *TaxedPrice `json:"tax,omitempty"`
+	Category *Category   `json:"category,omitempty"`
+	Status   string      `json:"status,omitempty"`
+	// PrimaryImage is the first image of the gallery
+	PrimaryImage *Media `json:"primary_image,omitempty"`
+	// Score is the search relevance, only set when searching with q
+	Score *float64 `json:"score,omitempty"`
 }
 
 type Category struct {
@@ -33,14 +261,294 @@ type Category struct {
 }
 
 type ProductDetail struct {
+	Code        string `json:"code"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Brand       string `json:"brand,omitempty"`
+	// Locale is the locale the texts were found in, empty without a title
+	Locale string `json:"locale,omitempty"`
+	Price  Price  `json:"price"`
+	// SalePrice is the price after the promotions in PromotionIDs, both are
+	// only set when a promotion applies
+	SalePrice    *Price `json:"sale_price,omitempty"`
+	PromotionIDs []uint `json:"promotion_ids,omitempty"`
+	// Tax splits the selling price into net, tax and gross, only set when
+	// a country was asked for
+	Tax      *TaxedPrice `json:"tax,omitempty"`
+	Category *Category   `json:"category,omitempty"`
+	Status   string      `json:"status,omitempty"`
+	// TaxClass is the class set on the product itself, empty when it
+	// inherits the one of its category
+	TaxClass string `json:"tax_class,omitempty"`
+	// PublishFrom and PublishUntil are the publishing window, unset sides
+	// are open
+	PublishFrom  *time.Time      `json:"publish_from,omitempty"`
+	PublishUntil *time.Time      `json:"publish_until,omitempty"`
+	Options      []OptionDetail  `json:"options,omitempty"`
+	Gallery      []Media         `json:"gallery,omitempty"`
+	Variants     []VariantDetail `json:"variants"`
+	// AsOf is the moment the prices were in effect, only set when asked for
+	// historical prices. Variants created after it are left out.
+	AsOf *time.Time `json:"as_of,omitempty"`
+}
+
+// PriceHistoryResponse lists the base prices of a product and its variants
+// over time, oldest first
+type PriceHistoryResponse struct {
+	Code     string                `json:"code"`
+	Prices   []PricePeriod         `json:"prices"`
+	Variants []VariantPriceHistory `json:"variants"`
+}
+
+type VariantPriceHistory struct {
+	SKU    string        `json:"sku"`
+	Prices []PricePeriod `json:"prices"`
+}
+
+// PricePeriod is a price in effect from ValidFrom until ValidTo, a missing
+// ValidTo marks the current price
+type PricePeriod struct {
+	// Price is null for periods a variant inherited the product price
+	Price     *Price     `json:"price"`
+	ValidFrom time.Time  `json:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to"`
+}
+
+// TaxedPrice is a selling price with the taxes of a country
+type TaxedPrice struct {
+	Country string `json:"country"`
+	Class   string `json:"class"`
+	// Rate is the VAT percentage of the class in the country
+	Rate  decimal.Decimal `json:"rate"`
+	Net   Price           `json:"net"`
+	Tax   Price           `json:"tax"`
+	Gross Price           `json:"gross"`
+}
+
+type VariantDetail struct {
+	Name  string `json:"name"`
+	SKU   string `json:"sku"`
+	Price Price  `json:"price"`
+	// SalePrice is the price after the promotions in PromotionIDs, both are
+	// only set when a promotion applies
+	SalePrice    *Price `json:"sale_price,omitempty"`
+	PromotionIDs []uint `json:"promotion_ids,omitempty"`
+	// Tax splits the selling price into net, tax and gross, only set when
+	// a country was asked for
+	Tax       *TaxedPrice `json:"tax,omitempty"`
+	Quantity  int         `json:"quantity"`
+	Available bool        `json:"available"`
+	// Attributes maps option names to the value of this variant
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// OptionDetail is an option axis of a product with its values in order
+type OptionDetail struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductOptions replaces all option axes of a product, it's also the response
+type ProductOptions struct {
+	Options []OptionDetail `json:"options"`
+}
+
+// Media references an image in the external media store
+type Media struct {
+	ID       uint   `json:"id"`
+	URL      string `json:"url"`
+	AltText  string `json:"alt_text,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	Position int    `json:"position"`
+	// Variant is the sku of the variant the image shows, if any
+	Variant string `json:"variant,omitempty"`
+}
+
+type MediaListResponse struct {
+	Media []Media `json:"media"`
+}
+
+// MediaRequest adds or replaces an image reference. Without a position new
+// images go to the end of the gallery and updated ones keep their place.
+type MediaRequest struct {
+	URL      string `json:"url"`
+	AltText  string `json:"alt_text"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Position *int   `json:"position,omitempty"`
+	Variant  string `json:"variant,omitempty"`
+}
+
+type CreateProductRequest struct {
 	Code     string          `json:"code"`
-	Price    float64         `json:"price"`
-	Category *Category       `json:"category,omitempty"`
+	Price    decimal.Decimal `json:"price"`
+	Category string          `json:"category,omitempty"`
+	Brand    string          `json:"brand,omitempty"`
+	// Status defaults to draft, so new products are not public right away
+	Status       string     `json:"status,omitempty"`
+	PublishFrom  *time.Time `json:"publish_from,omitempty"`
+	PublishUntil *time.Time `json:"publish_until,omitempty"`
+	// TaxClass is empty to inherit the tax class of the category
+	TaxClass string `json:"tax_class,omitempty"`
+}
+
+// UpdateProductRequest is used by both PUT and PATCH. PUT requires a price,
+// keeps the status and clears the category, publishing window and tax class
+// when they are omitted. PATCH only touches the given fields and clears the
+// category, publish times and tax class when they are set to an empty string.
+type UpdateProductRequest struct {
+	Price        *decimal.Decimal `json:"price,omitempty"`
+	Category     *string          `json:"category,omitempty"`
+	Brand        *string          `json:"brand,omitempty"`
+	Status       *string          `json:"status,omitempty"`
+	PublishFrom  *string          `json:"publish_from,omitempty"`
+	PublishUntil *string          `json:"publish_until,omitempty"`
+	TaxClass     *string          `json:"tax_class,omitempty"`
+}
+
+// TranslationRequest sets the texts of a product in one locale
+type TranslationRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type TranslationResponse struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type VariantsListResponse struct {
 	Variants []VariantDetail `json:"variants"`
 }
 
-type VariantDetail struct {
-	Name  string  `json:"name"`
-	SKU   string  `json:"sku"`
-	Price float64 `json:"price"`
+// VariantRequest is used to create and replace variants. A missing or null
+// price stores no variant price, so the variant inherits the product price.
+// On update an empty sku keeps the current one.
+type VariantRequest struct {
+	Name  string              `json:"name"`
+	SKU   string              `json:"sku"`
+	Price decimal.NullDecimal `json:"price"`
+	// Attributes maps option names of the product to one of their values,
+	// they replace the current attributes of the variant
+	Attributes map[string]string `json:"attributes"`
+}
+
+// StockResponse is the stock of a variant, in total and per warehouse
+type StockResponse struct {
+	SKU        string           `json:"sku"`
+	Quantity   int              `json:"quantity"`
+	Available  bool             `json:"available"`
+	Warehouses []WarehouseStock `json:"warehouses"`
+}
+
+type WarehouseStock struct {
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+}
+
+// StockAdjustmentRequest adds Delta to the stock of a variant, a negative
+// delta removes stock. Without a warehouse the default warehouse is used.
+type StockAdjustmentRequest struct {
+	Warehouse string `json:"warehouse"`
+	Delta     int    `json:"delta"`
+}
+
+// QuoteRequest asks for the prices of a basket
+type QuoteRequest struct {
+	Items []QuoteItem `json:"items"`
+}
+
+type QuoteItem struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// QuoteResponse prices a basket the way the product details do. Skus that
+// can't be quoted are listed in Errors and don't count towards Subtotal.
+type QuoteResponse struct {
+	Currency string       `json:"currency"`
+	Lines    []QuoteLine  `json:"lines"`
+	Subtotal Price        `json:"subtotal"`
+	Errors   []QuoteError `json:"errors,omitempty"`
+}
+
+type QuoteLine struct {
+	SKU     string `json:"sku"`
+	Product string `json:"product"`
+	Title   string `json:"title,omitempty"`
+	Name    string `json:"name"`
+	// UnitPrice, SalePrice and PromotionIDs are the variant prices of the
+	// product detail
+	UnitPrice    Price  `json:"unit_price"`
+	SalePrice    *Price `json:"sale_price,omitempty"`
+	PromotionIDs []uint `json:"promotion_ids,omitempty"`
+	Quantity     int    `json:"quantity"`
+	// LineTotal is the sale price, or the unit price without one, times
+	// the quantity
+	LineTotal Price `json:"line_total"`
+}
+
+type QuoteError struct {
+	SKU   string `json:"sku"`
+	Error string `json:"error"`
+}
+
+// ImportRow is one row of a CSV or NDJSON import, a product with at most one
+// of its variants. Products spread over several rows, one per variant. Empty
+// fields keep what's stored, so a product only needs a price when it's new.
+type ImportRow struct {
+	Code         string              `json:"code"`
+	Brand        string              `json:"brand"`
+	Price        decimal.NullDecimal `json:"price"`
+	Category     string              `json:"category"`
+	Status       string              `json:"status"`
+	TaxClass     string              `json:"tax_class"`
+	SKU          string              `json:"sku"`
+	VariantName  string              `json:"variant_name"`
+	VariantPrice decimal.NullDecimal `json:"variant_price"`
+}
+
+// ImportOptions controls an import
+type ImportOptions struct {
+	// Format is csv or ndjson
+	Format string
+	// DryRun validates and counts every row without writing anything
+	DryRun bool
+	// BatchSize is the number of rows written per transaction
+	BatchSize int
+}
+
+// ImportReport tells what an import did, or would do on a dry run. Rows
+// with errors are skipped, the other rows are imported anyway. Products and
+// variants are counted once per batch that touches them.
+type ImportReport struct {
+	DryRun   bool          `json:"dry_run"`
+	Rows     int           `json:"rows"`
+	Products ImportCounts  `json:"products"`
+	Variants ImportCounts  `json:"variants"`
+	Errors   []ImportError `json:"errors"`
+}
+
+type ImportCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ImportError is the reason a row was skipped, Line is where the row starts
+// in the file
+type ImportError struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code,omitempty"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ExportProduct is a line of the NDJSON export, a product of the list with
+// all its variants
+type ExportProduct struct {
+	Product
+	Variants []VariantDetail `json:"variants"`
 }
@@ -0,0 +1,209 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+// exportColumns are the CSV columns of an export, a row per variant. A product
+// without variants gets one row with the variant columns left empty. The tax
+// columns split the selling price of the row, the variant's if there is one,
+// and are only filled when a country was asked for.
+var exportColumns = []string{
+	"code", "title", "brand", "category", "status", "currency", "price", "sale_price",
+	"sku", "variant_name", "variant_price", "variant_sale_price", "quantity",
+	"tax_class", "tax_rate", "net", "tax", "gross",
+}
+
+// HandleExport serves GET /catalog/export?format=csv|ndjson, csv is the
+// default. It takes the filters and sort of GET /catalog and the currency,
+// market, locale and country parameters, and streams every matching product.
+func (h *CatalogHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseProductFilter(query)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Country = parseCountry(r)
+
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = FormatCSV
+	}
+
+	writer, err := newExportWriter(w, format)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// The response starts with the first chunk, so errors before it still get
+	// an error response
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		w.Header().Set("Content-Type", writer.contentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	err = h.service.ExportProducts(filter, opts, func(products []ExportProduct) error {
+		start()
+		if err := writer.write(products); err != nil {
+			return err
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			writeServiceError(w, err)
+			return
+		}
+		// The status is sent already. Aborting the response makes the client
+		// see a broken download rather than a file that looks complete.
+		log.Printf("Export failed: %s", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	// An empty export still gets its CSV header
+	start()
+	if err := writer.flush(); err != nil {
+		log.Printf("Export failed: %s", err)
+	}
+}
+
+// exportWriter writes the products of an export in one format
+type exportWriter interface {
+	contentType() string
+	// write writes a chunk of products through to the response
+	write(products []ExportProduct) error
+	// flush finishes the export
+	flush() error
+}
+
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, ErrExportFormat
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+	// header tells if the header row is written
+	header bool
+}
+
+func (c *csvExportWriter) contentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *csvExportWriter) write(products []ExportProduct) error {
+	c.writeHeader()
+
+	for _, p := range products {
+		product := []string{
+			p.Code, p.Title, p.Brand, "", p.Status, p.Price.Currency,
+			exportAmount(&p.Price), exportAmount(p.SalePrice),
+		}
+		if p.Category != nil {
+			product[3] = p.Category.Code
+		}
+
+		if len(p.Variants) == 0 {
+			row := append(product, "", "", "", "", "")
+			c.writer.Write(append(row, exportTax(p.Tax)...))
+			continue
+		}
+
+		for _, v := range p.Variants {
+			row := append(product[:len(product):len(product)],
+				v.SKU, v.Name, exportAmount(&v.Price), exportAmount(v.SalePrice), strconv.Itoa(v.Quantity))
+			c.writer.Write(append(row, exportTax(v.Tax)...))
+		}
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvExportWriter) flush() error {
+	c.writeHeader()
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvExportWriter) writeHeader() {
+	if !c.header {
+		c.writer.Write(exportColumns)
+		c.header = true
+	}
+}
+
+// exportAmount writes a price with two decimals, the currency has a column
+// of its own
+func exportAmount(price *Price) string {
+	if price == nil {
+		return ""
+	}
+	return price.Amount.StringFixed(2)
+}
+
+// exportTax fills the tax columns of a row, they are empty without taxes
+func exportTax(tax *TaxedPrice) []string {
+	if tax == nil {
+		return []string{"", "", "", "", ""}
+	}
+	return []string{
+		tax.Class, tax.Rate.String(),
+		exportAmount(&tax.Net), exportAmount(&tax.Tax), exportAmount(&tax.Gross),
+	}
+}
+
+// ndjsonExportWriter writes a product with its variants per line, prices
+// follow the priceFormat parameter like in the API
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonExportWriter) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (n *ndjsonExportWriter) write(products []ExportProduct) error {
+	for _, p := range products {
+		if err := n.encoder.Encode(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonExportWriter) flush() error {
+	return nil
+}
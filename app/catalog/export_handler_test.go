@@ -0,0 +1,202 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestProducts serves count products with ascending ids, keyset
+// pages continue after the id in filter.After
+func newExportTestProducts(count int, calls *[]models.ProductFilter) *mockProductsRepo {
+	return &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			*calls = append(*calls, filter)
+
+			first := 1
+			if len(filter.After) > 0 {
+				first = int(filter.After[0].(uint)) + 1
+			}
+
+			var products []models.Product
+			for id := first; id <= count && len(products) < limit; id++ {
+				products = append(products, models.Product{
+					ID:    uint(id),
+					Code:  fmt.Sprintf("PROD%04d", id),
+					Price: decimal.NewFromInt(int64(id)),
+				})
+			}
+			return products, 0, nil
+		},
+	}
+}
+
+func TestHandleExport_CSV(t *testing.T) {
+	category := &models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			if len(filter.After) > 0 {
+				return nil, 0, nil
+			}
+			return []models.Product{
+				{
+					ID: 1, Code: "PROD001", Brand: "Acme", Price: decimal.RequireFromString("10.99"), Status: models.ProductStatusActive,
+					CategoryID: &category.ID, Category: category,
+					Variants: []models.Variant{
+						{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("11.99"), Quantity: 3},
+						{ID: 2, ProductID: 1, Name: "Blue, dark", SKU: "SKU001-B", Price: decimal.Zero},
+					},
+				},
+				{ID: 2, Code: "PROD002", Price: decimal.NewFromInt(20), Status: models.ProductStatusActive},
+			}, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+	handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="catalog.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "code,title,brand,category,status,currency,price,sale_price,sku,variant_name,variant_price,variant_sale_price,quantity,tax_class,tax_rate,net,tax,gross\n"+
+		"PROD001,,Acme,CLOTHING,active,EUR,10.99,,SKU001-R,Red,11.99,,3,,,,,\n"+
+		"PROD001,,Acme,CLOTHING,active,EUR,10.99,,SKU001-B,\"Blue, dark\",10.99,,0,,,,,\n"+
+		"PROD002,,,,active,EUR,20.00,,,,,,,,,,,\n", w.Body.String())
+}
+
+func TestHandleExport_CSVTaxes(t *testing.T) {
+	reduced := models.TaxClassReduced
+	repo := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			if len(filter.After) > 0 {
+				return nil, 0, nil
+			}
+			return []models.Product{
+				{
+					ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.00"), Status: models.ProductStatusActive,
+					Variants: []models.Variant{
+						{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("20.00")},
+					},
+				},
+				{ID: 2, Code: "PROD002", Price: decimal.NewFromInt(5), Status: models.ProductStatusActive, TaxClass: &reduced},
+			}, 0, nil
+		},
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+	handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export?country=DE", nil))
+
+	// A variant row is taxed on the variant price
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "code,title,brand,category,status,currency,price,sale_price,sku,variant_name,variant_price,variant_sale_price,quantity,tax_class,tax_rate,net,tax,gross\n"+
+		"PROD001,,,,active,EUR,10.00,,SKU001-R,Red,20.00,,0,standard,19,20.00,3.80,23.80\n"+
+		"PROD002,,,,active,EUR,5.00,,,,,,,reduced,7,5.00,0.35,5.35\n", w.Body.String())
+}
+
+func TestHandleExport_NDJSONChunks(t *testing.T) {
+	var calls []models.ProductFilter
+	repo := newExportTestProducts(exportChunkSize+1, &calls)
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+	handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export?format=ndjson&category=CLOTHING&currency=GBP&priceFormat=exact", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	// The second chunk continues after the last product of the first one and
+	// both keep the filters of the list
+	require.Len(t, calls, 2)
+	assert.Empty(t, calls[0].After)
+	assert.Equal(t, []any{uint(exportChunkSize)}, calls[1].After)
+	for _, filter := range calls {
+		assert.Equal(t, "CLOTHING", filter.Category)
+		assert.True(t, filter.SkipTotal)
+	}
+
+	var products []ExportProduct
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var p ExportProduct
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+		products = append(products, p)
+	}
+	require.Len(t, products, exportChunkSize+1)
+	assert.Equal(t, "PROD0001", products[0].Code)
+	assert.Equal(t, "PROD0501", products[exportChunkSize].Code)
+	assert.Equal(t, "0.85", products[0].Price.Amount.String())
+	assert.Equal(t, "GBP", products[0].Price.Currency)
+	assert.Empty(t, products[0].Variants)
+}
+
+func TestHandleExport_Empty(t *testing.T) {
+	handler := NewCatalogHandler(NewCatalogService(&mockProductsRepo{}, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+	handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export?format=CSV", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "code,title,brand,category,status,currency,price,sale_price,sku,variant_name,variant_price,variant_sale_price,quantity,tax_class,tax_rate,net,tax,gross\n", w.Body.String())
+}
+
+func TestHandleExport_Errors(t *testing.T) {
+	failing := &mockProductsRepo{
+		getPaginationFn: func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+			return nil, 0, errors.New("connection reset")
+		},
+	}
+
+	tests := []struct {
+		name   string
+		repo   *mockProductsRepo
+		query  string
+		status int
+	}{
+		{"unknown format", &mockProductsRepo{}, "?format=xml", http.StatusBadRequest},
+		{"invalid filter", &mockProductsRepo{}, "?priceMin=abc", http.StatusBadRequest},
+		{"unknown currency", &mockProductsRepo{}, "?currency=XYZ", http.StatusBadRequest},
+		{"failed first chunk", failing, "", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCatalogHandler(NewCatalogService(tt.repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+			w := httptest.NewRecorder()
+			handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export"+tt.query, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestHandleExport_FailedLaterChunk(t *testing.T) {
+	var calls []models.ProductFilter
+	repo := newExportTestProducts(exportChunkSize+1, &calls)
+	first := repo.getPaginationFn
+	repo.getPaginationFn = func(offset, limit int, filter models.ProductFilter) ([]models.Product, int64, error) {
+		if len(filter.After) > 0 {
+			return nil, 0, errors.New("connection reset")
+		}
+		return first(offset, limit, filter)
+	}
+
+	handler := NewCatalogHandler(NewCatalogService(repo, newTestCategories(), newTestPriceLists(), testTaxRates))
+	w := httptest.NewRecorder()
+
+	// The first chunk is sent already, the response is aborted instead
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.HandleExport(w, httptest.NewRequest("GET", "/catalog/export", nil))
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
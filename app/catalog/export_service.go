@@ -0,0 +1,54 @@
+package catalog
+
+import "github.com/mytheresa/go-hiring-challenge/models"
+
+// exportChunkSize is the number of products an export reads per query
+const exportChunkSize = 500
+
+// ExportProducts walks the filtered catalog in keyset order and hands it to
+// write one chunk at a time, so an export never holds more than a chunk in
+// memory. Prices are resolved like in ListProducts.
+func (s *CatalogService) ExportProducts(filter models.ProductFilter, opts ViewOptions, write func([]ExportProduct) error) error {
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return err
+	}
+
+	if prices.tax, err = s.newTaxation(opts.Country); err != nil {
+		return err
+	}
+
+	filter.Prices = prices.view()
+	filter.Promotions = prices.promotions
+	filter.SkipTotal = true
+
+	for {
+		products, _, err := s.repo.GetProductsWithPagination(0, exportChunkSize, filter)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return nil
+		}
+
+		if err := prices.load(s.prices, products...); err != nil {
+			return err
+		}
+
+		chunk := make([]ExportProduct, len(products))
+		for i, p := range products {
+			chunk[i] = mapProductToExportDTO(p, prices, opts.Locales)
+		}
+
+		if err := write(chunk); err != nil {
+			return err
+		}
+
+		if len(products) < exportChunkSize {
+			return nil
+		}
+
+		// The next chunk continues after the last product, like a cursor
+		filter.After = keysetValues(filter.Sort, products[len(products)-1], prices)
+	}
+}
@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
+)
+
+// maxImportBody is the largest body POST /catalog/import reads, 32 MiB.
+// Bigger files go through cmd/import.
+const maxImportBody = 32 << 20
+
+type ImportHandler struct {
+	service *ImportService
+}
+
+func NewImportHandler(service *ImportService) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+	}
+}
+
+// HandleImport serves POST /catalog/import?format=csv|ndjson&dryRun=true&batchSize=500.
+// Without a format the Content-Type decides, text/csv or application/x-ndjson.
+// The report lists the rows that failed, the others are imported anyway.
+// A body over maxImportBody gets 413, the batches written before the limit
+// was reached stay imported.
+func (h *ImportHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.ContentLength > maxImportBody {
+		writeServiceError(w, ErrImportTooLarge)
+		return
+	}
+
+	query := r.URL.Query()
+
+	opts := ImportOptions{
+		Format:    query.Get("format"),
+		DryRun:    utils.ParseBoolParam(query.Get("dryRun"), false),
+		BatchSize: utils.ParseIntParam(query.Get("batchSize"), defaultImportBatchSize),
+	}
+	if opts.Format == "" {
+		opts.Format = importFormatOf(r.Header.Get("Content-Type"))
+	}
+
+	body := &importBody{reader: http.MaxBytesReader(w, r.Body, maxImportBody)}
+	report, err := h.service.Import(api.ActorFromRequest(r), body, opts)
+	if body.tooLarge {
+		writeServiceError(w, ErrImportTooLarge)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, report)
+}
+
+// importFormatOf maps a Content-Type to its import format, empty when it's
+// none of them
+func importFormatOf(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// importBody notes when the request body went over maxImportBody, the
+// import itself only sees a failed read
+type importBody struct {
+	reader   io.Reader
+	tooLarge bool
+}
+
+func (b *importBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.tooLarge = true
+	}
+	return n, err
+}
@@ -0,0 +1,253 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockImportStore struct {
+	products []models.Product
+	importFn func([]models.Product, []*models.AuditEntry) error
+	// batches and entries record every successful ImportProducts call
+	batches [][]models.Product
+	entries []*models.AuditEntry
+}
+
+func (m *mockImportStore) GetProductsForImport(codes, skus []string) ([]models.Product, error) {
+	var found []models.Product
+	for _, p := range m.products {
+		owns := slices.ContainsFunc(p.Variants, func(v models.Variant) bool { return slices.Contains(skus, v.SKU) })
+		if slices.Contains(codes, p.Code) || owns {
+			p.Variants = slices.Clone(p.Variants)
+			found = append(found, p)
+		}
+	}
+	return found, nil
+}
+
+func (m *mockImportStore) ImportProducts(products []models.Product, entries []*models.AuditEntry) error {
+	if m.importFn != nil {
+		if err := m.importFn(products, entries); err != nil {
+			return err
+		}
+	}
+	m.batches = append(m.batches, products)
+	m.entries = append(m.entries, entries...)
+	return nil
+}
+
+// newTestImportStore has PROD001 with the variant SKU001-R and PROD002 with
+// SKU002-A
+func newTestImportStore() *mockImportStore {
+	clothing := uint(1)
+	return &mockImportStore{products: []models.Product{
+		{
+			ID: 1, Code: "PROD001", Price: decimal.RequireFromString("10.99"), Brand: "Acme", Status: models.ProductStatusActive,
+			CategoryID: &clothing, Category: &models.Category{ID: 1, Code: "CLOTHING"},
+			Variants: []models.Variant{{ID: 1, ProductID: 1, SKU: "SKU001-R", Name: "Red"}},
+		},
+		{
+			ID: 2, Code: "PROD002", Price: decimal.RequireFromString("20"), Status: models.ProductStatusActive,
+			Variants: []models.Variant{{ID: 2, ProductID: 2, SKU: "SKU002-A", Name: "One size", Price: decimal.RequireFromString("22")}},
+		},
+	}}
+}
+
+func postImport(handler *ImportHandler, query, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/catalog/import"+query, bytes.NewBufferString(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Actor", "importer@example.com")
+	w := httptest.NewRecorder()
+	handler.HandleImport(w, req)
+	return w
+}
+
+func TestHandleImport_CSV(t *testing.T) {
+	store := newTestImportStore()
+	handler := NewImportHandler(NewImportService(store, newTestCategories()))
+
+	body := "code,price,brand,category,sku,variant_name,variant_price\n" +
+		"prod001,12.50,,,SKU001-R,,\n" +
+		"PROD001,,,,SKU001-B,Blue,13\n" +
+		"PROD100,19.90,Newco,shoes,SKU100-A,Small,\n" +
+		"PROD100,,,,SKU100-B,Large,21.90\n" +
+		"PROD101,,,,,,\n" +
+		"PROD102,5,,HATS,,,\n" +
+		"PROD103,5,,,SKU002-A,Stolen,\n" +
+		"PROD104,abc,,,,,\n" +
+		"PROD105,1.001,,,,,\n" +
+		"PROD106,5,,,,Orphan,\n" +
+		"PROD002,20,,,SKU002-A,,\n"
+	w := postImport(handler, "", "text/csv; charset=utf-8", body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report ImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.False(t, report.DryRun)
+	assert.Equal(t, 11, report.Rows)
+	assert.Equal(t, ImportCounts{Created: 1, Updated: 1, Unchanged: 1}, report.Products)
+	assert.Equal(t, ImportCounts{Created: 3, Unchanged: 2}, report.Variants)
+	assert.Equal(t, []ImportError{
+		{Line: 6, Code: "PROD101", Error: ErrImportPriceRequired.Error()},
+		{Line: 7, Code: "PROD102", Error: "category not found: HATS"},
+		{Line: 8, Code: "PROD103", SKU: "SKU002-A", Error: "sku belongs to another product: PROD002"},
+		{Line: 9, Code: "PROD104", Error: `invalid row: price "abc" is not a decimal`},
+		{Line: 10, Code: "PROD105", Error: ErrProductPricePrecision.Error()},
+		{Line: 11, Code: "PROD106", Error: ErrImportVariantNoSKU.Error()},
+	}, report.Errors)
+
+	// One transaction writes both changed products, the unchanged PROD002 is
+	// left alone
+	require.Len(t, store.batches, 1)
+	written := store.batches[0]
+	require.Len(t, written, 2)
+
+	assert.Equal(t, "PROD001", written[0].Code)
+	assert.Equal(t, uint(1), written[0].ID)
+	assert.Equal(t, "12.5", written[0].Price.String())
+	assert.Equal(t, "Acme", written[0].Brand)
+	// Only the new variant is written, Red didn't change
+	require.Len(t, written[0].Variants, 1)
+	assert.Equal(t, "SKU001-B", written[0].Variants[0].SKU)
+
+	assert.Equal(t, "PROD100", written[1].Code)
+	assert.Equal(t, models.ProductStatusDraft, written[1].Status)
+	require.NotNil(t, written[1].CategoryID)
+	assert.Equal(t, uint(2), *written[1].CategoryID)
+	require.Len(t, written[1].Variants, 2)
+	assert.True(t, written[1].Variants[0].Price.IsZero())
+	assert.Equal(t, "21.9", written[1].Variants[1].Price.String())
+
+	// Every change is audited as the caller
+	var audited []string
+	for _, e := range store.entries {
+		assert.Equal(t, "importer@example.com", e.Actor)
+		audited = append(audited, e.Action+" "+e.Entity+" "+e.Code)
+	}
+	assert.Equal(t, []string{
+		"update product PROD001",
+		"create variant SKU001-B",
+		"create product PROD100",
+		"create variant SKU100-A",
+		"create variant SKU100-B",
+	}, audited)
+	assert.Equal(t, map[string]models.AuditChange{"price": {Before: "10.99", After: "12.50"}}, store.entries[0].Diff())
+}
+
+func TestHandleImport_NDJSONDryRun(t *testing.T) {
+	store := newTestImportStore()
+	handler := NewImportHandler(NewImportService(store, newTestCategories()))
+
+	// With a batch per row the later rows only find PROD200 because the dry
+	// run remembers it
+	body := `{"code": "PROD200", "price": "15", "tax_class": "reduced", "sku": "SKU200-A", "variant_name": "A"}
+
+{"code": "PROD200", "sku": "SKU200-B", "variant_name": "B", "variant_price": 16}
+{"code": "PROD200", "sku": "SKU200-A", "variant_price": 17}
+{"code": "PROD001", "status": "archived"}
+{"code": "PROD001", "colour": "red"}
+not json
+`
+	w := postImport(handler, "?dryRun=true&batchSize=1", "application/x-ndjson", body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, store.batches)
+
+	var report ImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 6, report.Rows)
+	// PROD200 is counted by each batch, the later two only change variants
+	assert.Equal(t, ImportCounts{Created: 1, Updated: 1, Unchanged: 2}, report.Products)
+	assert.Equal(t, ImportCounts{Created: 2, Updated: 1}, report.Variants)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, 6, report.Errors[0].Line)
+	assert.Contains(t, report.Errors[0].Error, `unknown field "colour"`)
+	assert.Equal(t, 7, report.Errors[1].Line)
+}
+
+func TestHandleImport_FailedBatch(t *testing.T) {
+	store := newTestImportStore()
+	store.importFn = func(products []models.Product, entries []*models.AuditEntry) error {
+		if products[0].Code == "PROD301" {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	handler := NewImportHandler(NewImportService(store, newTestCategories()))
+
+	body := "code,price\nPROD300,1\nPROD301,2\nPROD302,3\n"
+	w := postImport(handler, "?format=csv&batchSize=1", "", body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report ImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	// The failed batch is rolled back and doesn't count, the next one runs
+	assert.Equal(t, ImportCounts{Created: 2}, report.Products)
+	assert.Equal(t, []ImportError{{Line: 3, Code: "PROD301", Error: "connection reset"}}, report.Errors)
+	assert.Len(t, store.batches, 2)
+}
+
+func TestHandleImport_Invalid(t *testing.T) {
+	handler := NewImportHandler(NewImportService(newTestImportStore(), newTestCategories()))
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+	}{
+		{"no format", "", "application/json", "code\nPROD001\n"},
+		{"unknown format", "?format=xml", "", "<products/>"},
+		{"empty csv", "?format=csv", "", ""},
+		{"unknown column", "?format=csv", "", "code,colour\nPROD001,red\n"},
+		{"repeated column", "", "text/csv", "code,price,Price\nPROD001,1,2\n"},
+		{"no code column", "", "text/csv", "sku,price\nSKU001-R,1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postImport(handler, tt.query, tt.contentType, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandleImport_TooLarge(t *testing.T) {
+	store := newTestImportStore()
+	handler := NewImportHandler(NewImportService(store, newTestCategories()))
+	body := "code,brand\nPROD001," + strings.Repeat("x", maxImportBody)
+
+	t.Run("announced length", func(t *testing.T) {
+		w := postImport(handler, "", "text/csv", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("streamed body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/catalog/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+
+		handler.HandleImport(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	assert.Empty(t, store.batches)
+}
@@ -0,0 +1,182 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Formats of imports and exports
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// importColumns are the CSV columns and NDJSON keys of an ImportRow, only
+// code is required
+var importColumns = []string{"code", "brand", "price", "category", "status", "tax_class", "sku", "variant_name", "variant_price"}
+
+// maxImportLine is the longest NDJSON line an import reads
+const maxImportLine = 1 << 20
+
+// importReader reads the rows of an import one at a time. A row it can't
+// read comes back with an error wrapping ErrImportRowInvalid, the reader
+// carries on after it. Any other error ends the import.
+type importReader interface {
+	// next returns the row and the line it starts on, io.EOF after the last
+	next() (ImportRow, int, error)
+}
+
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return newCSVImportReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+		return &ndjsonImportReader{scanner: scanner}, nil
+	}
+	return nil, ErrImportFormat
+}
+
+type csvImportReader struct {
+	reader *csv.Reader
+	// columns holds the column name of every field
+	columns []string
+}
+
+// newCSVImportReader reads the header, which names the columns in any order.
+// Unknown and repeated columns are rejected so a typo doesn't silently drop
+// a field.
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrImportHeader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportHeader, err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		// Spreadsheets like to start the file with a byte order mark
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrImportHeader, name)
+		}
+		if slices.Contains(columns[:i], name) {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrImportHeader, name)
+		}
+		columns[i] = name
+	}
+
+	if !slices.Contains(columns, "code") {
+		return nil, fmt.Errorf("%w: the code column is required", ErrImportHeader)
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) next() (ImportRow, int, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return ImportRow{}, parseErr.StartLine, fmt.Errorf("%w: %v", ErrImportRowInvalid, parseErr.Err)
+		}
+		return ImportRow{}, 0, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+
+	var row ImportRow
+	for i, value := range record {
+		switch c.columns[i] {
+		case "code":
+			row.Code = value
+		case "brand":
+			row.Brand = value
+		case "price":
+			row.Price, err = parseImportPrice("price", value)
+		case "category":
+			row.Category = value
+		case "status":
+			row.Status = value
+		case "tax_class":
+			row.TaxClass = value
+		case "sku":
+			row.SKU = value
+		case "variant_name":
+			row.VariantName = value
+		case "variant_price":
+			row.VariantPrice, err = parseImportPrice("variant_price", value)
+		}
+		if err != nil {
+			return row, line, err
+		}
+	}
+
+	return row, line, nil
+}
+
+// parseImportPrice reads a price cell, an empty one is no price
+func parseImportPrice(column, value string) (decimal.NullDecimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+
+	price, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, fmt.Errorf("%w: %s %q is not a decimal", ErrImportRowInvalid, column, value)
+	}
+	return decimal.NewNullDecimal(price), nil
+}
+
+// ndjsonImportReader reads one JSON object per line, blank lines are skipped
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonImportReader) next() (ImportRow, int, error) {
+	for n.scanner.Scan() {
+		n.line++
+
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		var row ImportRow
+		if err := decoder.Decode(&row); err != nil {
+			return ImportRow{}, n.line, fmt.Errorf("%w: %v", ErrImportRowInvalid, err)
+		}
+		if decoder.More() {
+			return ImportRow{}, n.line, fmt.Errorf("%w: one JSON object per line", ErrImportRowInvalid)
+		}
+		return row, n.line, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return ImportRow{}, n.line + 1, err
+	}
+	return ImportRow{}, 0, io.EOF
+}
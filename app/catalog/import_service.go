@@ -0,0 +1,480 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// Rows are written in transactions of defaultImportBatchSize unless the
+// options ask for another size, at most maxImportBatchSize
+const (
+	defaultImportBatchSize = 500
+	maxImportBatchSize     = 5000
+)
+
+type ImportService struct {
+	store      ImportStore
+	categories CategoriesFinder
+}
+
+func NewImportService(store ImportStore, categories CategoriesFinder) *ImportService {
+	return &ImportService{
+		store:      store,
+		categories: categories,
+	}
+}
+
+// Import reads the rows of r and upserts them batch by batch, products by
+// code and variants by sku. A row that fails is reported and skipped. A batch
+// is one transaction, when writing it fails all of its rows are reported and
+// the import carries on with the next batch.
+func (s *ImportService) Import(actor models.Actor, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	rows, err := newImportReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultImportBatchSize
+	}
+	if batchSize > maxImportBatchSize {
+		batchSize = maxImportBatchSize
+	}
+
+	run := &importRun{
+		service:    s,
+		actor:      actor,
+		report:     &ImportReport{DryRun: opts.DryRun, Errors: []ImportError{}},
+		categories: make(map[string]*models.Category),
+	}
+	if opts.DryRun {
+		run.carried = make(map[string]*models.Product)
+		run.carriedSKUs = make(map[string]string)
+	}
+
+	var batch []importLine
+	for {
+		row, line, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, ErrImportRowInvalid) {
+			// The rest of the input can't be read, what was imported so far stays
+			run.report.Errors = append(run.report.Errors, ImportError{Line: line, Error: err.Error()})
+			break
+		}
+
+		run.report.Rows++
+		if err != nil {
+			run.fail(importLine{row: row, line: line}, err)
+			continue
+		}
+
+		batch = append(batch, importLine{row: normalizeImportRow(row), line: line})
+		if len(batch) == batchSize {
+			run.importBatch(batch)
+			batch = nil
+		}
+	}
+	run.importBatch(batch)
+
+	// Rows that can't be read are reported right away, the others once their
+	// batch ran
+	slices.SortStableFunc(run.report.Errors, func(a, b ImportError) int { return a.Line - b.Line })
+	return run.report, nil
+}
+
+type importLine struct {
+	row  ImportRow
+	line int
+}
+
+// importRun holds the state of one import across its batches
+type importRun struct {
+	service    *ImportService
+	actor      models.Actor
+	report     *ImportReport
+	categories map[string]*models.Category
+	// carried keeps the products a dry run would have written, by code, so
+	// later batches see them like a real run would see them in the database.
+	// carriedSKUs has the code of the carried product owning each sku.
+	carried     map[string]*models.Product
+	carriedSKUs map[string]string
+}
+
+// importedProduct is a product as a batch leaves it, with the snapshots of
+// what was stored before. Before is nil for new products and variants.
+type importedProduct struct {
+	product        *models.Product
+	before         map[string]any
+	variantsBefore map[string]map[string]any
+	// skus are the variants the batch touched, in order
+	skus  []string
+	lines []importLine
+}
+
+// importBatch applies the rows to the products they touch and writes every
+// product that changed, together with its changed variants
+func (r *importRun) importBatch(lines []importLine) {
+	var valid []importLine
+	for _, l := range lines {
+		if err := validateImportRow(l.row); err != nil {
+			r.fail(l, err)
+			continue
+		}
+		valid = append(valid, l)
+	}
+	if len(valid) == 0 {
+		return
+	}
+
+	products, owners, err := r.loadProducts(valid)
+	if err != nil {
+		r.failAll(valid, err)
+		return
+	}
+
+	var touched []string
+	for _, l := range valid {
+		item, err := r.apply(products, owners, l.row)
+		if err != nil {
+			r.fail(l, err)
+			continue
+		}
+		if len(item.lines) == 0 {
+			touched = append(touched, item.product.Code)
+		}
+		item.lines = append(item.lines, l)
+	}
+
+	var (
+		writes        []models.Product
+		entries       []*models.AuditEntry
+		applied       []importLine
+		productCounts ImportCounts
+		variantCounts ImportCounts
+	)
+
+	for _, code := range touched {
+		item := products[code]
+		applied = append(applied, item.lines...)
+
+		changed := count(&productCounts, r.entry(models.AuditEntityProduct, code, item.before, productSnapshot(item.product)), &entries)
+
+		write := *item.product
+		write.Variants = nil
+		for _, sku := range item.skus {
+			variant := findVariant(item.product, sku)
+			entry := r.entry(models.AuditEntityVariant, sku, item.variantsBefore[sku], variantSnapshot(code, variant))
+			if count(&variantCounts, entry, &entries) {
+				write.Variants = append(write.Variants, *variant)
+				changed = true
+			}
+		}
+
+		if changed {
+			writes = append(writes, write)
+		}
+	}
+
+	if r.carried != nil {
+		for _, code := range touched {
+			r.carried[code] = products[code].product
+			for _, v := range products[code].product.Variants {
+				r.carriedSKUs[v.SKU] = code
+			}
+		}
+	} else if len(writes) > 0 {
+		if err := r.service.store.ImportProducts(writes, entries); err != nil {
+			switch {
+			case errors.Is(err, models.ErrDuplicateKey):
+				err = ErrImportSKUTaken
+			case errors.Is(err, models.ErrCategoryDeleted):
+				err = ErrCategoryNotFound
+			}
+			r.failAll(applied, err)
+			return
+		}
+	}
+
+	addCounts(&r.report.Products, productCounts)
+	addCounts(&r.report.Variants, variantCounts)
+}
+
+// loadProducts loads the products the rows name or whose variants they name,
+// keyed by code, and the code of the product owning each of their skus
+func (r *importRun) loadProducts(lines []importLine) (map[string]*importedProduct, map[string]string, error) {
+	var codes, skus []string
+	for _, l := range lines {
+		if !slices.Contains(codes, l.row.Code) {
+			codes = append(codes, l.row.Code)
+		}
+		if l.row.SKU != "" && !slices.Contains(skus, l.row.SKU) {
+			skus = append(skus, l.row.SKU)
+		}
+	}
+
+	stored, err := r.service.store.GetProductsForImport(codes, skus)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loaded := make(map[string]*models.Product, len(stored))
+	for i := range stored {
+		loaded[stored[i].Code] = &stored[i]
+	}
+	// What a dry run would have written already is newer than the database
+	for _, sku := range skus {
+		if code, ok := r.carriedSKUs[sku]; ok && !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range codes {
+		if product, ok := r.carried[code]; ok {
+			loaded[code] = cloneProduct(product)
+		}
+	}
+
+	products := make(map[string]*importedProduct, len(loaded))
+	owners := make(map[string]string)
+	for code, product := range loaded {
+		item := &importedProduct{
+			product:        product,
+			before:         productSnapshot(product),
+			variantsBefore: make(map[string]map[string]any, len(product.Variants)),
+		}
+		for i := range product.Variants {
+			variant := &product.Variants[i]
+			item.variantsBefore[variant.SKU] = variantSnapshot(code, variant)
+			owners[variant.SKU] = code
+		}
+		products[code] = item
+	}
+
+	return products, owners, nil
+}
+
+// apply checks a row against the stored products and, when it's fine,
+// copies its non-empty fields onto its product and variant
+func (r *importRun) apply(products map[string]*importedProduct, owners map[string]string, row ImportRow) (*importedProduct, error) {
+	item := products[row.Code]
+	if item == nil && !row.Price.Valid {
+		return nil, ErrImportPriceRequired
+	}
+
+	var category *models.Category
+	if row.Category != "" {
+		var err error
+		if category, err = r.findCategory(row.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	if row.SKU != "" {
+		if owner, ok := owners[row.SKU]; ok && owner != row.Code {
+			return nil, fmt.Errorf("%w: %s", ErrImportSKUTaken, owner)
+		}
+		if _, ok := owners[row.SKU]; !ok && row.VariantName == "" {
+			return nil, ErrVariantNameRequired
+		}
+	}
+
+	if item == nil {
+		item = &importedProduct{
+			product:        &models.Product{Code: row.Code, Status: models.ProductStatusDraft},
+			variantsBefore: make(map[string]map[string]any),
+		}
+		products[row.Code] = item
+	}
+
+	product := item.product
+	if row.Price.Valid {
+		product.Price = row.Price.Decimal
+	}
+	if row.Brand != "" {
+		product.Brand = row.Brand
+	}
+	if category != nil {
+		product.CategoryID = &category.ID
+		product.Category = category
+	}
+	if row.Status != "" {
+		product.Status = row.Status
+	}
+	if row.TaxClass != "" {
+		product.TaxClass = parseTaxClass(row.TaxClass)
+	}
+
+	if row.SKU == "" {
+		return item, nil
+	}
+
+	variant := findVariant(product, row.SKU)
+	if variant == nil {
+		product.Variants = append(product.Variants, models.Variant{ProductID: product.ID, SKU: row.SKU})
+		variant = &product.Variants[len(product.Variants)-1]
+		owners[row.SKU] = row.Code
+	}
+	if row.VariantName != "" {
+		variant.Name = row.VariantName
+	}
+	if row.VariantPrice.Valid {
+		variant.Price = row.VariantPrice.Decimal
+	}
+	if !slices.Contains(item.skus, row.SKU) {
+		item.skus = append(item.skus, row.SKU)
+	}
+
+	return item, nil
+}
+
+// entry is the audit entry of an imported product or variant, nil when it
+// didn't change
+func (r *importRun) entry(entity, code string, before, after map[string]any) *models.AuditEntry {
+	action := models.AuditActionUpdate
+	if before == nil {
+		action = models.AuditActionCreate
+	}
+
+	entry := models.NewAuditEntry(r.actor, action, entity, code)
+	entry.Before = before
+	entry.After = after
+	if before != nil && len(entry.Diff()) == 0 {
+		return nil
+	}
+	return entry
+}
+
+func (r *importRun) findCategory(code string) (*models.Category, error) {
+	if category, ok := r.categories[code]; ok {
+		return category, nil
+	}
+
+	category, err := r.service.categories.GetCategoryByCode(code)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, code)
+		}
+		return nil, err
+	}
+
+	r.categories[code] = category
+	return category, nil
+}
+
+func (r *importRun) fail(l importLine, err error) {
+	r.report.Errors = append(r.report.Errors, ImportError{
+		Line:  l.line,
+		Code:  strings.ToUpper(strings.TrimSpace(l.row.Code)),
+		SKU:   strings.TrimSpace(l.row.SKU),
+		Error: err.Error(),
+	})
+}
+
+func (r *importRun) failAll(lines []importLine, err error) {
+	for _, l := range lines {
+		r.fail(l, err)
+	}
+}
+
+// count adds an entry to the counts and the entries to write, it tells
+// whether something changed
+func count(counts *ImportCounts, entry *models.AuditEntry, entries *[]*models.AuditEntry) bool {
+	switch {
+	case entry == nil:
+		counts.Unchanged++
+		return false
+	case entry.Action == models.AuditActionCreate:
+		counts.Created++
+	default:
+		counts.Updated++
+	}
+	*entries = append(*entries, entry)
+	return true
+}
+
+func addCounts(total *ImportCounts, batch ImportCounts) {
+	total.Created += batch.Created
+	total.Updated += batch.Updated
+	total.Unchanged += batch.Unchanged
+}
+
+// normalizeImportRow trims every field and brings codes, statuses and tax
+// classes to the case they are stored in. Skus are stored as sent.
+func normalizeImportRow(row ImportRow) ImportRow {
+	row.Code = strings.ToUpper(strings.TrimSpace(row.Code))
+	row.Brand = strings.TrimSpace(row.Brand)
+	row.Category = strings.ToUpper(strings.TrimSpace(row.Category))
+	row.Status = strings.ToLower(strings.TrimSpace(row.Status))
+	row.TaxClass = strings.ToLower(strings.TrimSpace(row.TaxClass))
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.VariantName = strings.TrimSpace(row.VariantName)
+	return row
+}
+
+// validateImportRow checks a row on its own, with the limits of the product
+// and variant endpoints
+func validateImportRow(row ImportRow) error {
+	if row.Code == "" {
+		return ErrProductCodeRequired
+	}
+	if len(row.Code) > 32 {
+		return ErrProductCodeTooLong
+	}
+	if len(row.Brand) > 128 {
+		return ErrProductBrandTooLong
+	}
+	if row.Price.Valid {
+		if err := validatePrice(row.Price.Decimal); err != nil {
+			return err
+		}
+	}
+	if row.Status != "" && !validStatus(row.Status) {
+		return ErrProductStatusInvalid
+	}
+	if !validTaxClass(row.TaxClass) {
+		return ErrTaxClassInvalid
+	}
+
+	if row.SKU == "" {
+		if row.VariantName != "" || row.VariantPrice.Valid {
+			return ErrImportVariantNoSKU
+		}
+		return nil
+	}
+	if len(row.SKU) > 32 {
+		return ErrVariantSKUTooLong
+	}
+	if len(row.VariantName) > 256 {
+		return ErrVariantNameTooLong
+	}
+	if row.VariantPrice.Valid {
+		if err := validatePrice(row.VariantPrice.Decimal); err != nil {
+			return ErrVariantPriceInvalid
+		}
+	}
+
+	return nil
+}
+
+func findVariant(product *models.Product, sku string) *models.Variant {
+	for i := range product.Variants {
+		if product.Variants[i].SKU == sku {
+			return &product.Variants[i]
+		}
+	}
+	return nil
+}
+
+// cloneProduct copies a product deep enough for an import to change it
+func cloneProduct(product *models.Product) *models.Product {
+	clone := *product
+	clone.Variants = slices.Clone(product.Variants)
+	return &clone
+}
@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+type MediaHandler struct {
+	service *MediaService
+}
+
+func NewMediaHandler(service *MediaService) *MediaHandler {
+	return &MediaHandler{
+		service: service,
+	}
+}
+
+func (h *MediaHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	response, err := h.service.ListMedia(r.PathValue("code"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *MediaHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req MediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreateMedia(api.ActorFromRequest(r), r.PathValue("code"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, response)
+}
+
+func (h *MediaHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseMediaID(r)
+	if !ok {
+		writeServiceError(w, ErrMediaIDInvalid)
+		return
+	}
+
+	var req MediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.UpdateMedia(api.ActorFromRequest(r), r.PathValue("code"), id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *MediaHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseMediaID(r)
+	if !ok {
+		writeServiceError(w, ErrMediaIDInvalid)
+		return
+	}
+
+	if err := h.service.DeleteMedia(api.ActorFromRequest(r), r.PathValue("code"), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseMediaID(r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,242 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMediaRepo struct {
+	media    []models.ProductMedia
+	createFn func(*models.ProductMedia) error
+	updateFn func(*models.ProductMedia) error
+	deleteFn func(uint, uint) error
+
+	// entries collects the audit entries passed to the writes
+	entries []*models.AuditEntry
+}
+
+func (m *mockMediaRepo) GetProductMedia(productID uint) ([]models.ProductMedia, error) {
+	return m.media, nil
+}
+
+func (m *mockMediaRepo) GetMedia(productID, id uint) (*models.ProductMedia, error) {
+	for _, media := range m.media {
+		if media.ProductID == productID && media.ID == id {
+			return &media, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *mockMediaRepo) CreateMedia(media *models.ProductMedia, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.createFn != nil {
+		return m.createFn(media)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockMediaRepo) UpdateMedia(media *models.ProductMedia, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.updateFn != nil {
+		return m.updateFn(media)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockMediaRepo) DeleteMedia(productID, id uint, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteFn != nil {
+		return m.deleteFn(productID, id)
+	}
+	return errors.New("not implemented")
+}
+
+func newMediaTestProducts() *mockProductsRepo {
+	return &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code == "PROD001" {
+				return &models.Product{
+					ID:    1,
+					Code:  "PROD001",
+					Price: decimal.NewFromFloat(10.99),
+					Variants: []models.Variant{
+						{ID: 7, ProductID: 1, Name: "Red", SKU: "SKU001-R"},
+					},
+				}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+	}
+}
+
+func newMediaRequest(method, url, id, body string) *http.Request {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	req.SetPathValue("code", "PROD001")
+	req.SetPathValue("id", id)
+	return req
+}
+
+func TestMediaHandleList(t *testing.T) {
+	variant := &models.Variant{ID: 7, SKU: "SKU001-R"}
+	media := &mockMediaRepo{media: []models.ProductMedia{
+		{ID: 1, ProductID: 1, URL: "https://media.example.com/front.jpg", AltText: "Front", Width: 1200, Height: 1600},
+		{ID: 2, ProductID: 1, VariantID: &variant.ID, Variant: variant, URL: "https://media.example.com/red.jpg", Position: 1},
+	}}
+
+	handler := NewMediaHandler(NewMediaService(newMediaTestProducts(), media))
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, newMediaRequest("GET", "/catalog/PROD001/media", "", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"media": [
+		{"id": 1, "url": "https://media.example.com/front.jpg", "alt_text": "Front", "width": 1200, "height": 1600, "position": 0},
+		{"id": 2, "url": "https://media.example.com/red.jpg", "position": 1, "variant": "SKU001-R"}
+	]}`, w.Body.String())
+}
+
+func TestMediaHandleCreate(t *testing.T) {
+	media := &mockMediaRepo{
+		media: []models.ProductMedia{{ID: 1, ProductID: 1, URL: "https://media.example.com/front.jpg", Position: 3}},
+		createFn: func(media *models.ProductMedia) error {
+			media.ID = 2
+			return nil
+		},
+	}
+	handler := NewMediaHandler(NewMediaService(newMediaTestProducts(), media))
+
+	t.Run("appends to the gallery", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCreate(w, newMediaRequest("POST", "/catalog/PROD001/media", "",
+			`{"url":" https://media.example.com/red.jpg ","alt_text":"Red","variant":"SKU001-R"}`))
+
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var resp Media
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, uint(2), resp.ID)
+		assert.Equal(t, "https://media.example.com/red.jpg", resp.URL)
+		assert.Equal(t, 4, resp.Position)
+		assert.Equal(t, "SKU001-R", resp.Variant)
+	})
+
+	tests := []struct {
+		name   string
+		code   string
+		body   string
+		status int
+	}{
+		{"explicit position", "PROD001", `{"url":"https://media.example.com/a.jpg","position":0}`, http.StatusCreated},
+		{"missing url", "PROD001", `{"alt_text":"Front"}`, http.StatusBadRequest},
+		{"relative url", "PROD001", `{"url":"/images/a.jpg"}`, http.StatusBadRequest},
+		{"unsupported scheme", "PROD001", `{"url":"ftp://media.example.com/a.jpg"}`, http.StatusBadRequest},
+		{"negative width", "PROD001", `{"url":"https://media.example.com/a.jpg","width":-1}`, http.StatusBadRequest},
+		{"negative position", "PROD001", `{"url":"https://media.example.com/a.jpg","position":-1}`, http.StatusBadRequest},
+		{"variant of another product", "PROD001", `{"url":"https://media.example.com/a.jpg","variant":"SKU002-R"}`, http.StatusBadRequest},
+		{"unknown product", "UNKNOWN", `{"url":"https://media.example.com/a.jpg"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newMediaRequest("POST", "/catalog/"+tt.code+"/media", "", tt.body)
+			req.SetPathValue("code", tt.code)
+			w := httptest.NewRecorder()
+
+			handler.HandleCreate(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestMediaHandleUpdate(t *testing.T) {
+	variant := &models.Variant{ID: 7, SKU: "SKU001-R"}
+	media := &mockMediaRepo{
+		media: []models.ProductMedia{
+			{ID: 1, ProductID: 1, VariantID: &variant.ID, Variant: variant, URL: "https://media.example.com/red.jpg", Position: 2},
+		},
+		updateFn: func(media *models.ProductMedia) error {
+			return nil
+		},
+	}
+	handler := NewMediaHandler(NewMediaService(newMediaTestProducts(), media))
+
+	t.Run("keeps position and detaches variant", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleUpdate(w, newMediaRequest("PUT", "/catalog/PROD001/media/1", "1",
+			`{"url":"https://media.example.com/front.jpg","alt_text":"Front"}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id": 1, "url": "https://media.example.com/front.jpg", "alt_text": "Front", "position": 2}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"unknown media", "9", http.StatusNotFound},
+		{"invalid id", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleUpdate(w, newMediaRequest("PUT", "/catalog/PROD001/media/"+tt.id, tt.id,
+				`{"url":"https://media.example.com/front.jpg"}`))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestMediaHandleDelete(t *testing.T) {
+	media := &mockMediaRepo{
+		media: []models.ProductMedia{
+			{ID: 1, ProductID: 1, URL: "https://media.example.com/front.jpg"},
+		},
+		deleteFn: func(productID, id uint) error {
+			assert.Equal(t, uint(1), productID)
+			if id == 1 {
+				return nil
+			}
+			return models.ErrNotFound
+		},
+	}
+	handler := NewMediaHandler(NewMediaService(newMediaTestProducts(), media))
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"existing media", "1", http.StatusNoContent},
+		{"unknown media", "2", http.StatusNotFound},
+		{"invalid id", "0", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleDelete(w, newMediaRequest("DELETE", "/catalog/PROD001/media/"+tt.id, tt.id, ""))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	require.Len(t, media.entries, 1)
+	assert.Equal(t, models.AuditActionDeleteMedia, media.entries[0].Action)
+	assert.Equal(t, "PROD001", media.entries[0].Code)
+	assert.Equal(t, "https://media.example.com/front.jpg", media.entries[0].Before["url"])
+	assert.Nil(t, media.entries[0].After)
+}
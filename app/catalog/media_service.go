@@ -0,0 +1,202 @@
+package catalog
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type MediaService struct {
+	products ProductsReader
+	media    MediaStore
+}
+
+func NewMediaService(products ProductsReader, media MediaStore) *MediaService {
+	return &MediaService{
+		products: products,
+		media:    media,
+	}
+}
+
+func (s *MediaService) ListMedia(code string) (*MediaListResponse, error) {
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.media.GetProductMedia(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &MediaListResponse{
+		Media: make([]Media, len(media)),
+	}
+	for i, m := range media {
+		response.Media[i] = mapMediaToDTO(m)
+	}
+
+	return response, nil
+}
+
+func (s *MediaService) CreateMedia(actor models.Actor, code string, req MediaRequest) (*Media, error) {
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, err
+	}
+
+	media := &models.ProductMedia{ProductID: product.ID}
+	if err := applyMediaRequest(product, media, req); err != nil {
+		return nil, err
+	}
+
+	if req.Position == nil {
+		existing, err := s.media.GetProductMedia(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			media.Position = existing[len(existing)-1].Position + 1
+		}
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionCreateMedia, models.AuditEntityProduct, product.Code)
+	entry.After = mediaSnapshot(media)
+
+	if err := s.media.CreateMedia(media, entry); err != nil {
+		return nil, err
+	}
+
+	dto := mapMediaToDTO(*media)
+	return &dto, nil
+}
+
+// UpdateMedia replaces the reference and metadata of an image, sending no
+// variant detaches it from its variant
+func (s *MediaService) UpdateMedia(actor models.Actor, code string, id uint, req MediaRequest) (*Media, error) {
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	product, media, err := s.getMedia(code, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionUpdateMedia, models.AuditEntityProduct, product.Code)
+	entry.Before = mediaSnapshot(media)
+
+	if err := applyMediaRequest(product, media, req); err != nil {
+		return nil, err
+	}
+
+	entry.After = mediaSnapshot(media)
+
+	if err := s.media.UpdateMedia(media, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+
+	dto := mapMediaToDTO(*media)
+	return &dto, nil
+}
+
+// DeleteMedia only removes the reference, the file stays in the media store
+func (s *MediaService) DeleteMedia(actor models.Actor, code string, id uint) error {
+	product, media, err := s.getMedia(code, id)
+	if err != nil {
+		return err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionDeleteMedia, models.AuditEntityProduct, product.Code)
+	entry.Before = mediaSnapshot(media)
+
+	if err := s.media.DeleteMedia(product.ID, media.ID, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrMediaNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *MediaService) getMedia(code string, id uint) (*models.Product, *models.ProductMedia, error) {
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	media, err := s.media.GetMedia(product.ID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, nil, ErrMediaNotFound
+		}
+		return nil, nil, err
+	}
+
+	return product, media, nil
+}
+
+// applyMediaRequest copies a validated request onto media, resolving the
+// variant sku among the variants of the product
+func applyMediaRequest(product *models.Product, media *models.ProductMedia, req MediaRequest) error {
+	media.URL = strings.TrimSpace(req.URL)
+	media.AltText = strings.TrimSpace(req.AltText)
+	media.Width = req.Width
+	media.Height = req.Height
+	media.VariantID = nil
+	media.Variant = nil
+
+	if req.Position != nil {
+		media.Position = *req.Position
+	}
+
+	if sku := strings.TrimSpace(req.Variant); sku != "" {
+		idx := slices.IndexFunc(product.Variants, func(v models.Variant) bool {
+			return v.SKU == sku
+		})
+		if idx < 0 {
+			return ErrMediaVariantNotFound
+		}
+		media.VariantID = &product.Variants[idx].ID
+		media.Variant = &product.Variants[idx]
+	}
+
+	return nil
+}
+
+func (s *MediaService) validateRequest(req MediaRequest) error {
+	raw := strings.TrimSpace(req.URL)
+	if raw == "" {
+		return ErrMediaURLRequired
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(raw) > 2048 {
+		return ErrMediaURLInvalid
+	}
+
+	if len(strings.TrimSpace(req.AltText)) > 256 {
+		return ErrMediaAltTextTooLong
+	}
+
+	if req.Width < 0 || req.Height < 0 {
+		return ErrMediaDimensionsInvalid
+	}
+
+	if req.Position != nil && *req.Position < 0 {
+		return ErrMediaPositionInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+type QuotesHandler struct {
+	service *QuotesService
+}
+
+func NewQuotesHandler(service *QuotesService) *QuotesHandler {
+	return &QuotesHandler{
+		service: service,
+	}
+}
+
+// HandleCreate serves POST /quotes, it takes the currency, market,
+// priceFormat and locale parameters of /catalog/{code}
+func (h *QuotesHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreateQuote(req, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
@@ -0,0 +1,156 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuoteTestProducts() *mockProductsRepo {
+	categoryID := uint(2)
+	products := []models.Product{
+		{
+			ID: 1, Code: "PROD001", Price: decimal.RequireFromString("100"), CategoryID: &categoryID,
+			Translations: []models.ProductTranslation{{Locale: "en", Title: "Linen shirt"}},
+			Variants: []models.Variant{
+				{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.RequireFromString("80")},
+				{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+			},
+		},
+		{
+			ID: 5, Code: "PROD005", Price: decimal.RequireFromString("10.99"),
+			Variants: []models.Variant{
+				{ID: 9, ProductID: 5, Name: "One size", SKU: "SKU005-A"},
+			},
+		},
+	}
+
+	find := func(code string) (*models.Product, error) {
+		for i := range products {
+			if products[i].Code == code {
+				return &products[i], nil
+			}
+		}
+		return nil, models.ErrNotFound
+	}
+
+	return &mockProductsRepo{
+		getByCodeFn: find,
+		getBySKUsFn: func(skus []string) ([]models.Product, error) {
+			var found []models.Product
+			for _, p := range products {
+				if slices.ContainsFunc(p.Variants, func(v models.Variant) bool { return slices.Contains(skus, v.SKU) }) {
+					found = append(found, p)
+				}
+			}
+			return found, nil
+		},
+	}
+}
+
+func TestHandleCreateQuote(t *testing.T) {
+	handler := NewQuotesHandler(NewQuotesService(newQuoteTestProducts(), newTestPriceLists()))
+
+	body := `{"items": [
+		{"sku": "SKU001-R", "quantity": 2},
+		{"sku": "SKU005-A", "quantity": 3},
+		{"sku": "NOPE", "quantity": 1},
+		{"sku": " SKU001-R ", "quantity": 1}
+	]}`
+	w := httptest.NewRecorder()
+	handler.HandleCreate(w, httptest.NewRequest("POST", "/quotes?priceFormat=exact", bytes.NewBufferString(body)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"currency": "EUR",
+		"lines": [
+			{"sku": "SKU001-R", "product": "PROD001", "title": "Linen shirt", "name": "Red", "quantity": 3,
+			 "unit_price": {"amount": "80.00", "currency": "EUR"}, "line_total": {"amount": "240.00", "currency": "EUR"}},
+			{"sku": "SKU005-A", "product": "PROD005", "name": "One size", "quantity": 3,
+			 "unit_price": {"amount": "10.99", "currency": "EUR"}, "line_total": {"amount": "32.97", "currency": "EUR"}}
+		],
+		"subtotal": {"amount": "272.97", "currency": "EUR"},
+		"errors": [{"sku": "NOPE", "error": "variant not found"}]
+	}`, w.Body.String())
+}
+
+// TestHandleCreateQuote_MatchesProductDetail checks the quote against the
+// product detail for converted prices, inherited prices and promotions
+func TestHandleCreateQuote_MatchesProductDetail(t *testing.T) {
+	products := newQuoteTestProducts()
+	prices := newPromotionTestPriceLists()
+	quotes := NewQuotesHandler(NewQuotesService(products, prices))
+	catalog := NewCatalogHandler(NewCatalogService(products, newTestCategories(), prices, testTaxRates))
+
+	body := `{"items": [{"sku": "SKU001-R", "quantity": 2}, {"sku": "SKU001-B", "quantity": 1}]}`
+	w := httptest.NewRecorder()
+	quotes.HandleCreate(w, httptest.NewRequest("POST", "/quotes?currency=GBP", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var quote QuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &quote))
+
+	req := httptest.NewRequest("GET", "/catalog/PROD001?currency=GBP", nil)
+	req.SetPathValue("code", "PROD001")
+	w = httptest.NewRecorder()
+	catalog.HandleGetByCode(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var detail ProductDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+
+	assert.Equal(t, "GBP", quote.Currency)
+	require.Len(t, quote.Lines, 2)
+	for i, line := range quote.Lines {
+		variant := detail.Variants[i]
+		assert.Equal(t, variant.SKU, line.SKU)
+		assert.True(t, variant.Price.Amount.Equal(line.UnitPrice.Amount), line.SKU)
+		require.NotNil(t, line.SalePrice)
+		assert.True(t, variant.SalePrice.Amount.Equal(line.SalePrice.Amount), line.SKU)
+		assert.Equal(t, variant.PromotionIDs, line.PromotionIDs)
+	}
+
+	// Red sells at the converted fixed price of 42.50, Blue inherits 85.00
+	// and gets the category's 20% off
+	assert.Equal(t, "85", quote.Lines[0].LineTotal.Amount.String())
+	assert.Equal(t, "68", quote.Lines[1].LineTotal.Amount.String())
+	assert.Equal(t, "153", quote.Subtotal.Amount.String())
+}
+
+func TestHandleCreateQuote_Validation(t *testing.T) {
+	handler := NewQuotesHandler(NewQuotesService(newQuoteTestProducts(), newTestPriceLists()))
+
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{"invalid body", "", `{"items": `},
+		{"no items", "", `{"items": []}`},
+		{"missing sku", "", `{"items": [{"sku": " ", "quantity": 1}]}`},
+		{"zero quantity", "", `{"items": [{"sku": "SKU001-R", "quantity": 0}]}`},
+		{"negative quantity", "", `{"items": [{"sku": "SKU001-R", "quantity": -2}]}`},
+		{"quantity too large", "", `{"items": [{"sku": "SKU001-R", "quantity": 10001}]}`},
+		{"overflowing quantity", "", `{"items": [{"sku": "SKU001-R", "quantity": 9223372036854775807}, {"sku": "SKU001-R", "quantity": 1}]}`},
+		{"repeated sku too large", "", `{"items": [{"sku": "SKU001-R", "quantity": 6000}, {"sku": "SKU001-R", "quantity": 5000}]}`},
+		{"too many items", "", `{"items": [` + strings.Repeat(`{"sku": "SKU001-R", "quantity": 1}, `, maxQuoteItems) + `{"sku": "SKU001-R", "quantity": 1}]}`},
+		{"unknown currency", "?currency=XXX", `{"items": [{"sku": "SKU001-R", "quantity": 1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleCreate(w, httptest.NewRequest("POST", "/quotes"+tt.query, bytes.NewBufferString(tt.body)))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
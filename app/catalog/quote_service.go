@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// A quote takes at most maxQuoteItems items, and a sku at most
+// maxQuoteQuantity pieces once its items are added up
+const (
+	maxQuoteItems    = 100
+	maxQuoteQuantity = 10000
+)
+
+type QuotesService struct {
+	products ProductsReader
+	prices   PriceLists
+}
+
+func NewQuotesService(products ProductsReader, prices PriceLists) *QuotesService {
+	return &QuotesService{
+		products: products,
+		prices:   prices,
+	}
+}
+
+// CreateQuote prices a basket of variants. The lines are taken from the same
+// product details /catalog/{code} returns, so a quote never disagrees with
+// them. Only variants of the public catalog are quoted, other skus are
+// reported as errors. The same sku sent several times is quoted as one line.
+func (s *QuotesService) CreateQuote(req QuoteRequest, opts ViewOptions) (*QuoteResponse, error) {
+	items, err := mergeQuoteItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]string, len(items))
+	for i, item := range items {
+		skus[i] = item.SKU
+	}
+
+	products, err := s.products.GetPublishedProductsBySKUs(skus)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := prices.load(s.prices, products...); err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]QuoteLine)
+	for i := range products {
+		detail := mapProductToDetailDTO(&products[i], prices, opts.Locales)
+		for _, v := range detail.Variants {
+			lines[v.SKU] = QuoteLine{
+				SKU:          v.SKU,
+				Product:      detail.Code,
+				Title:        detail.Title,
+				Name:         v.Name,
+				UnitPrice:    v.Price,
+				SalePrice:    v.SalePrice,
+				PromotionIDs: v.PromotionIDs,
+			}
+		}
+	}
+
+	response := &QuoteResponse{
+		Currency: prices.currency,
+		Lines:    []QuoteLine{},
+	}
+
+	subtotal := decimal.Zero
+	for _, item := range items {
+		line, ok := lines[item.SKU]
+		if !ok {
+			response.Errors = append(response.Errors, QuoteError{SKU: item.SKU, Error: ErrVariantNotFound.Error()})
+			continue
+		}
+
+		unit := selling(line.UnitPrice, line.SalePrice).Amount
+		total := unit.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		line.Quantity = item.Quantity
+		line.LineTotal = prices.price(total)
+		subtotal = subtotal.Add(total)
+
+		response.Lines = append(response.Lines, line)
+	}
+	response.Subtotal = prices.price(subtotal)
+
+	return response, nil
+}
+
+// mergeQuoteItems validates the items and adds up the quantities of repeated
+// skus, keeping the order the skus were first sent in. The quantity of a sku
+// must stay within maxQuoteQuantity.
+func mergeQuoteItems(items []QuoteItem) ([]QuoteItem, error) {
+	if len(items) == 0 {
+		return nil, ErrQuoteItemsRequired
+	}
+	if len(items) > maxQuoteItems {
+		return nil, ErrQuoteTooManyItems
+	}
+
+	merged := make([]QuoteItem, 0, len(items))
+	positions := make(map[string]int)
+
+	for _, item := range items {
+		sku := strings.TrimSpace(item.SKU)
+		if sku == "" {
+			return nil, ErrQuoteSKURequired
+		}
+		if item.Quantity <= 0 || item.Quantity > maxQuoteQuantity {
+			return nil, ErrQuoteQuantityInvalid
+		}
+
+		if i, ok := positions[sku]; ok {
+			// Both are at most maxQuoteQuantity, so the sum can't overflow
+			merged[i].Quantity += item.Quantity
+			if merged[i].Quantity > maxQuoteQuantity {
+				return nil, ErrQuoteQuantityInvalid
+			}
+			continue
+		}
+
+		positions[sku] = len(merged)
+		merged = append(merged, QuoteItem{SKU: sku, Quantity: item.Quantity})
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,44 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+type StockHandler struct {
+	service *StockService
+}
+
+func NewStockHandler(service *StockService) *StockHandler {
+	return &StockHandler{
+		service: service,
+	}
+}
+
+func (h *StockHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	response, err := h.service.GetStock(r.PathValue("code"), r.PathValue("sku"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *StockHandler) HandleAdjust(w http.ResponseWriter, r *http.Request) {
+	var req StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.AdjustStock(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("sku"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStockRepo struct {
+	levels   []models.StockLevel
+	adjustFn func(uint, string, int) (int, error)
+
+	// entries collects the audit entries passed to the writes
+	entries []*models.AuditEntry
+}
+
+func (m *mockStockRepo) GetStockLevels(variantID uint) ([]models.StockLevel, error) {
+	return m.levels, nil
+}
+
+func (m *mockStockRepo) AdjustStock(variantID uint, warehouse string, delta int, entry *models.AuditEntry) (int, error) {
+	m.entries = append(m.entries, entry)
+	if m.adjustFn != nil {
+		return m.adjustFn(variantID, warehouse, delta)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func newTestVariants() *mockVariantsRepo {
+	return &mockVariantsRepo{
+		getBySKUFn: func(sku string) (*models.Variant, error) {
+			switch sku {
+			case "SKU001-R":
+				return &models.Variant{ID: 7, ProductID: 1, Name: "Red", SKU: "SKU001-R"}, nil
+			case "SKU002-R":
+				return &models.Variant{ID: 8, ProductID: 2, Name: "Red", SKU: "SKU002-R"}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+	}
+}
+
+func newStockRequest(method, sku, body string) *http.Request {
+	req := httptest.NewRequest(method, "/catalog/PROD001/variants/"+sku+"/stock", bytes.NewBufferString(body))
+	req.SetPathValue("code", "PROD001")
+	req.SetPathValue("sku", sku)
+	return req
+}
+
+func TestStockHandleGet(t *testing.T) {
+	stock := &mockStockRepo{levels: []models.StockLevel{
+		{VariantID: 7, Warehouse: &models.Warehouse{Code: "DEFAULT"}, Quantity: 5},
+		{VariantID: 7, Warehouse: &models.Warehouse{Code: "MUNICH"}, Quantity: 2},
+	}}
+
+	handler := NewStockHandler(NewStockService(newTestProducts(), newTestVariants(), stock))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, newStockRequest("GET", "SKU001-R", ""))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"sku": "SKU001-R",
+		"quantity": 7,
+		"available": true,
+		"warehouses": [
+			{"warehouse": "DEFAULT", "quantity": 5},
+			{"warehouse": "MUNICH", "quantity": 2}
+		]
+	}`, w.Body.String())
+}
+
+func TestStockHandleGet_OtherProductsVariant(t *testing.T) {
+	handler := NewStockHandler(NewStockService(newTestProducts(), newTestVariants(), &mockStockRepo{}))
+	w := httptest.NewRecorder()
+
+	handler.HandleGet(w, newStockRequest("GET", "SKU002-R", ""))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStockHandleAdjust(t *testing.T) {
+	stock := &mockStockRepo{
+		adjustFn: func(variantID uint, warehouse string, delta int) (int, error) {
+			assert.Equal(t, uint(7), variantID)
+			assert.Equal(t, models.DefaultWarehouse, warehouse)
+			assert.Equal(t, -2, delta)
+			return 3, nil
+		},
+		levels: []models.StockLevel{{VariantID: 7, Warehouse: &models.Warehouse{Code: "DEFAULT"}, Quantity: 3}},
+	}
+
+	handler := NewStockHandler(NewStockService(newTestProducts(), newTestVariants(), stock))
+	w := httptest.NewRecorder()
+
+	handler.HandleAdjust(w, newStockRequest("POST", "SKU001-R", `{"delta": -2}`))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp StockResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.Quantity)
+	assert.True(t, resp.Available)
+
+	require.Len(t, stock.entries, 1)
+	assert.Equal(t, models.AuditActionAdjustStock, stock.entries[0].Action)
+	assert.Equal(t, models.AuditEntityVariant, stock.entries[0].Entity)
+	assert.Equal(t, "SKU001-R", stock.entries[0].Code)
+}
+
+func TestStockHandleAdjust_Errors(t *testing.T) {
+	stock := &mockStockRepo{
+		adjustFn: func(variantID uint, warehouse string, delta int) (int, error) {
+			if warehouse != models.DefaultWarehouse {
+				return 0, models.ErrNotFound
+			}
+			return 0, models.ErrInsufficientStock
+		},
+	}
+
+	handler := NewStockHandler(NewStockService(newTestProducts(), newTestVariants(), stock))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"insufficient stock", `{"delta": -10}`, http.StatusConflict},
+		{"zero delta", `{"delta": 0}`, http.StatusBadRequest},
+		{"unknown warehouse", `{"warehouse": "PARIS", "delta": 1}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleAdjust(w, newStockRequest("POST", "SKU001-R", tt.body))
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
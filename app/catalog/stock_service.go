@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type StockService struct {
+	products ProductsReader
+	variants VariantsStore
+	stock    StockStore
+}
+
+func NewStockService(products ProductsReader, variants VariantsStore, stock StockStore) *StockService {
+	return &StockService{
+		products: products,
+		variants: variants,
+		stock:    stock,
+	}
+}
+
+func (s *StockService) GetStock(code, sku string) (*StockResponse, error) {
+	_, variant, err := getVariant(s.products, s.variants, code, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stockResponse(variant)
+}
+
+// AdjustStock changes the stock of a variant in one warehouse. Removing more
+// than the warehouse holds fails with ErrInsufficientStock and changes nothing.
+func (s *StockService) AdjustStock(actor models.Actor, code, sku string, req StockAdjustmentRequest) (*StockResponse, error) {
+	if req.Delta == 0 {
+		return nil, ErrStockDeltaInvalid
+	}
+
+	warehouse := strings.ToUpper(strings.TrimSpace(req.Warehouse))
+	if warehouse == "" {
+		warehouse = models.DefaultWarehouse
+	}
+
+	_, variant, err := getVariant(s.products, s.variants, code, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository fills in the quantities, they're only known once adjusted
+	entry := models.NewAuditEntry(actor, models.AuditActionAdjustStock, models.AuditEntityVariant, variant.SKU)
+
+	if _, err := s.stock.AdjustStock(variant.ID, warehouse, req.Delta, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrInsufficientStock):
+			return nil, ErrInsufficientStock
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	return s.stockResponse(variant)
+}
+
+func (s *StockService) stockResponse(variant *models.Variant) (*StockResponse, error) {
+	levels, err := s.stock.GetStockLevels(variant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StockResponse{
+		SKU:        variant.SKU,
+		Warehouses: make([]WarehouseStock, len(levels)),
+	}
+
+	for i, l := range levels {
+		response.Warehouses[i] = WarehouseStock{Quantity: l.Quantity}
+		if l.Warehouse != nil {
+			response.Warehouses[i].Warehouse = l.Warehouse.Code
+		}
+		response.Quantity += l.Quantity
+	}
+	response.Available = response.Quantity > 0
+
+	return response, nil
+}
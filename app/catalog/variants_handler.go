@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+type VariantsHandler struct {
+	service *VariantsService
+}
+
+func NewVariantsHandler(service *VariantsService) *VariantsHandler {
+	return &VariantsHandler{
+		service: service,
+	}
+}
+
+func (h *VariantsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.service.ListVariants(r.PathValue("code"), opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *VariantsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreateVariant(api.ActorFromRequest(r), r.PathValue("code"), req, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, response)
+}
+
+func (h *VariantsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req VariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.UpdateVariant(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("sku"), req, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *VariantsHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteVariant(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("sku")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *VariantsHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseViewOptions(r)
+	if err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.service.RestoreVariant(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("sku"), opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
@@ -0,0 +1,351 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockVariantsRepo struct {
+	getByProductFn func(uint) ([]models.Variant, error)
+	getBySKUFn     func(string) (*models.Variant, error)
+	createFn       func(*models.Variant) error
+	updateFn       func(*models.Variant) error
+	deleteFn       func(uint) error
+	restoreFn      func(uint, string) error
+
+	// entries collects the audit entries passed to the writes
+	entries []*models.AuditEntry
+}
+
+func (m *mockVariantsRepo) GetVariantsByProductID(productID uint) ([]models.Variant, error) {
+	if m.getByProductFn != nil {
+		return m.getByProductFn(productID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockVariantsRepo) GetVariantBySKU(sku string) (*models.Variant, error) {
+	if m.getBySKUFn != nil {
+		return m.getBySKUFn(sku)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockVariantsRepo) CreateVariant(variant *models.Variant, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.createFn != nil {
+		return m.createFn(variant)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockVariantsRepo) UpdateVariant(variant *models.Variant, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.updateFn != nil {
+		return m.updateFn(variant)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockVariantsRepo) DeleteVariant(id uint, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteFn != nil {
+		return m.deleteFn(id)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockVariantsRepo) RestoreVariant(productID uint, sku string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.restoreFn != nil {
+		return m.restoreFn(productID, sku)
+	}
+	return errors.New("not implemented")
+}
+
+func newTestProducts() *mockProductsRepo {
+	return &mockProductsRepo{
+		getByCodeFn: func(code string) (*models.Product, error) {
+			if code == "PROD001" {
+				return &models.Product{
+					ID:    1,
+					Code:  "PROD001",
+					Price: decimal.NewFromFloat(10.99),
+					Options: []models.ProductOption{{ID: 1, Name: "Color", Values: []models.ProductOptionValue{
+						{ID: 11, OptionID: 1, Value: "Red"},
+						{ID: 12, OptionID: 1, Value: "Blue"},
+					}}},
+				}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+	}
+}
+
+func TestVariantsHandleList_InheritsPrice(t *testing.T) {
+	variants := &mockVariantsRepo{
+		getByProductFn: func(productID uint) ([]models.Variant, error) {
+			assert.Equal(t, uint(1), productID)
+			return []models.Variant{
+				{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.NewFromFloat(11.99)},
+				{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+			}, nil
+		},
+	}
+
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+	req := httptest.NewRequest("GET", "/catalog/PROD001/variants", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp VariantsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Variants, 2)
+	assert.Equal(t, 11.99, resp.Variants[0].Price.Amount.InexactFloat64())
+	assert.Equal(t, 10.99, resp.Variants[1].Price.Amount.InexactFloat64())
+}
+
+func TestVariantsHandleList_InheritsPriceListPrice(t *testing.T) {
+	variants := &mockVariantsRepo{
+		getByProductFn: func(productID uint) ([]models.Variant, error) {
+			return []models.Variant{
+				{ID: 1, ProductID: 1, Name: "Red", SKU: "SKU001-R", Price: decimal.NewFromFloat(11.99)},
+				{ID: 2, ProductID: 1, Name: "Blue", SKU: "SKU001-B"},
+			}, nil
+		},
+	}
+
+	prices := newTestPriceLists()
+	prices.entriesFn = func(productIDs []uint, currency, market string) ([]models.PriceListEntry, error) {
+		return []models.PriceListEntry{{ProductID: 1, Currency: "GBP", Amount: decimal.RequireFromString("9.49")}}, nil
+	}
+
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, prices))
+	req := httptest.NewRequest("GET", "/catalog/PROD001/variants?market=UK", nil)
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp VariantsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Variants, 2)
+	assert.Equal(t, "10.19", resp.Variants[0].Price.Amount.StringFixed(2))
+	assert.Equal(t, "9.49", resp.Variants[1].Price.Amount.StringFixed(2))
+}
+func TestVariantsHandleList_ProductNotFound(t *testing.T) {
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), &mockVariantsRepo{}, newTestPriceLists()))
+	req := httptest.NewRequest("GET", "/catalog/INVALID/variants", nil)
+	req.SetPathValue("code", "INVALID")
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVariantsHandleCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		createErr error
+		status    int
+	}{
+		{"with price", `{"name":"Red","sku":"SKU001-R","price":"11.99"}`, nil, http.StatusCreated},
+		{"inherited price", `{"name":"Red","sku":"SKU001-R"}`, nil, http.StatusCreated},
+		{"invalid json", `invalid json`, nil, http.StatusBadRequest},
+		{"missing name", `{"sku":"SKU001-R"}`, nil, http.StatusBadRequest},
+		{"missing sku", `{"name":"Red"}`, nil, http.StatusBadRequest},
+		{"zero price", `{"name":"Red","sku":"SKU001-R","price":"0"}`, nil, http.StatusBadRequest},
+		{"duplicate sku", `{"name":"Red","sku":"SKU001A"}`, models.ErrDuplicateKey, http.StatusConflict},
+		{"with attributes", `{"name":"Red","sku":"SKU001-R","attributes":{"color":"red"}}`, nil, http.StatusCreated},
+		{"unknown option", `{"name":"Red","sku":"SKU001-R","attributes":{"size":"M"}}`, nil, http.StatusBadRequest},
+		{"unknown value", `{"name":"Red","sku":"SKU001-R","attributes":{"color":"Green"}}`, nil, http.StatusBadRequest},
+		{"repeated option", `{"name":"Red","sku":"SKU001-R","attributes":{"color":"red","Color":"blue"}}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants := &mockVariantsRepo{
+				createFn: func(variant *models.Variant) error {
+					assert.Equal(t, uint(1), variant.ProductID)
+					return tt.createErr
+				},
+			}
+
+			handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+			req := httptest.NewRequest("POST", "/catalog/PROD001/variants", bytes.NewBufferString(tt.body))
+			req.SetPathValue("code", "PROD001")
+			w := httptest.NewRecorder()
+
+			handler.HandleCreate(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestVariantsHandleCreate_Attributes(t *testing.T) {
+	variants := &mockVariantsRepo{
+		createFn: func(variant *models.Variant) error {
+			require.Len(t, variant.Attributes, 1)
+			assert.Equal(t, uint(1), variant.Attributes[0].OptionID)
+			assert.Equal(t, uint(12), variant.Attributes[0].OptionValueID)
+			return nil
+		},
+	}
+
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+	body := `{"name":"Blue","sku":"SKU001-B","attributes":{"COLOR":" blue "}}`
+	req := httptest.NewRequest("POST", "/catalog/PROD001/variants", bytes.NewBufferString(body))
+	req.SetPathValue("code", "PROD001")
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp VariantDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]string{"Color": "Blue"}, resp.Attributes)
+}
+
+func TestVariantsHandleUpdate_ClearsPrice(t *testing.T) {
+	variants := &mockVariantsRepo{
+		getBySKUFn: func(sku string) (*models.Variant, error) {
+			return &models.Variant{ID: 1, ProductID: 1, Name: "Red", SKU: sku, Price: decimal.NewFromFloat(11.99)}, nil
+		},
+		updateFn: func(variant *models.Variant) error {
+			assert.Equal(t, "SKU001-R", variant.SKU)
+			assert.True(t, variant.Price.IsZero())
+			return nil
+		},
+	}
+
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+	req := httptest.NewRequest("PUT", "/catalog/PROD001/variants/SKU001-R", bytes.NewBufferString(`{"name":"Red","price":null}`))
+	req.SetPathValue("code", "PROD001")
+	req.SetPathValue("sku", "SKU001-R")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp VariantDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 10.99, resp.Price.Amount.InexactFloat64())
+}
+
+func TestVariantsHandleUpdate_OtherProduct(t *testing.T) {
+	variants := &mockVariantsRepo{
+		getBySKUFn: func(sku string) (*models.Variant, error) {
+			return &models.Variant{ID: 5, ProductID: 2, Name: "Red", SKU: sku}, nil
+		},
+	}
+
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+	req := httptest.NewRequest("PUT", "/catalog/PROD001/variants/SKU002A", bytes.NewBufferString(`{"name":"Red"}`))
+	req.SetPathValue("code", "PROD001")
+	req.SetPathValue("sku", "SKU002A")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVariantsHandleDelete(t *testing.T) {
+	variants := &mockVariantsRepo{
+		getBySKUFn: func(sku string) (*models.Variant, error) {
+			if sku == "SKU001A" {
+				return &models.Variant{ID: 1, ProductID: 1, SKU: sku}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+		deleteFn: func(id uint) error {
+			assert.Equal(t, uint(1), id)
+			return nil
+		},
+	}
+	handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+
+	t.Run("existing variant", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/catalog/PROD001/variants/SKU001A", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "SKU001A")
+		w := httptest.NewRecorder()
+
+		handler.HandleDelete(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unknown variant", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/catalog/PROD001/variants/UNKNOWN", nil)
+		req.SetPathValue("code", "PROD001")
+		req.SetPathValue("sku", "UNKNOWN")
+		w := httptest.NewRecorder()
+
+		handler.HandleDelete(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestVariantsHandleRestore(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		restoreErr error
+		status     int
+	}{
+		{"deleted variant", "PROD001", nil, http.StatusOK},
+		{"no deleted variant", "PROD001", models.ErrNotFound, http.StatusNotFound},
+		{"sku taken again", "PROD001", models.ErrDuplicateKey, http.StatusConflict},
+		{"deleted product", "PROD002", nil, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants := &mockVariantsRepo{
+				restoreFn: func(productID uint, sku string) error {
+					assert.Equal(t, uint(1), productID)
+					assert.Equal(t, "SKU001-R", sku)
+					return tt.restoreErr
+				},
+				getBySKUFn: func(sku string) (*models.Variant, error) {
+					return &models.Variant{ID: 7, ProductID: 1, Name: "Red", SKU: sku}, nil
+				},
+			}
+
+			handler := NewVariantsHandler(NewVariantsService(newTestProducts(), variants, newTestPriceLists()))
+			req := httptest.NewRequest("POST", "/admin/catalog/"+tt.code+"/variants/SKU001-R/restore", nil)
+			req.SetPathValue("code", tt.code)
+			req.SetPathValue("sku", "SKU001-R")
+			w := httptest.NewRecorder()
+
+			handler.HandleRestore(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"name": "Red", "sku": "SKU001-R", "price": 10.99, "quantity": 0, "available": false}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type VariantsService struct {
+	products ProductsReader
+	variants VariantsStore
+	prices   PriceLists
+}
+
+func NewVariantsService(products ProductsReader, variants VariantsStore, prices PriceLists) *VariantsService {
+	return &VariantsService{
+		products: products,
+		variants: variants,
+		prices:   prices,
+	}
+}
+
+func (s *VariantsService) ListVariants(code string, opts ViewOptions) (*VariantsListResponse, error) {
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.variants.GetVariantsByProductID(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := prices.load(s.prices, *product); err != nil {
+		return nil, err
+	}
+
+	productPrice := prices.product(*product)
+	variantDTOs := make([]VariantDetail, len(variants))
+	for i, v := range variants {
+		variantDTOs[i] = mapVariantToDetailDTO(v, *product, productPrice, prices)
+	}
+
+	return &VariantsListResponse{
+		Variants: variantDTOs,
+	}, nil
+}
+
+func (s *VariantsService) CreateVariant(actor models.Actor, code string, req VariantRequest, opts ViewOptions) (*VariantDetail, error) {
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := buildAttributes(product, req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	variant := &models.Variant{
+		ProductID:  product.ID,
+		Name:       req.Name,
+		SKU:        req.SKU,
+		Price:      req.Price.Decimal,
+		Attributes: attributes,
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionCreate, models.AuditEntityVariant, variant.SKU)
+	entry.After = variantSnapshot(product.Code, variant)
+
+	if err := s.variants.CreateVariant(variant, entry); err != nil {
+		if errors.Is(err, models.ErrDuplicateKey) {
+			return nil, ErrVariantAlreadyExists
+		}
+		return nil, err
+	}
+
+	return s.mapVariant(product, variant, prices)
+}
+
+// UpdateVariant replaces the name and price of a variant. Sending no price
+// clears the variant price, so it inherits the product price again.
+func (s *VariantsService) UpdateVariant(actor models.Actor, code, sku string, req VariantRequest, opts ViewOptions) (*VariantDetail, error) {
+	if req.SKU == "" {
+		req.SKU = sku
+	}
+
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product, variant, err := s.getVariant(code, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := buildAttributes(product, req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	// A renamed sku is logged under the new one, the old one is in the changes
+	entry := models.NewAuditEntry(actor, models.AuditActionUpdate, models.AuditEntityVariant, req.SKU)
+	entry.Before = variantSnapshot(product.Code, variant)
+
+	variant.Name = req.Name
+	variant.SKU = req.SKU
+	variant.Price = req.Price.Decimal
+	variant.Attributes = attributes
+
+	entry.After = variantSnapshot(product.Code, variant)
+
+	if err := s.variants.UpdateVariant(variant, entry); err != nil {
+		if errors.Is(err, models.ErrDuplicateKey) {
+			return nil, ErrVariantAlreadyExists
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrVariantNotFound
+		}
+		return nil, err
+	}
+
+	return s.mapVariant(product, variant, prices)
+}
+
+func (s *VariantsService) DeleteVariant(actor models.Actor, code, sku string) error {
+	product, variant, err := s.getVariant(code, sku)
+	if err != nil {
+		return err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionDelete, models.AuditEntityVariant, variant.SKU)
+	entry.Before = variantSnapshot(product.Code, variant)
+
+	if err := s.variants.DeleteVariant(variant.ID, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrVariantNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RestoreVariant brings back a deleted variant of a product that is not
+// deleted itself
+func (s *VariantsService) RestoreVariant(actor models.Actor, code, sku string, opts ViewOptions) (*VariantDetail, error) {
+	prices, err := newPricing(s.prices, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := getProduct(s.products, code)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionRestore, models.AuditEntityVariant, sku)
+	entry.Before = deletedSnapshot(true)
+	entry.After = deletedSnapshot(false)
+
+	if err := s.variants.RestoreVariant(product.ID, sku, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrVariantNotFound
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrVariantAlreadyExists
+		}
+		return nil, err
+	}
+
+	_, variant, err := s.getVariant(code, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapVariant(product, variant, prices)
+}
+
+// mapVariant prices a variant in the response currency
+func (s *VariantsService) mapVariant(product *models.Product, variant *models.Variant, prices *pricing) (*VariantDetail, error) {
+	if err := prices.load(s.prices, *product); err != nil {
+		return nil, err
+	}
+
+	dto := mapVariantToDetailDTO(*variant, *product, prices.product(*product), prices)
+	return &dto, nil
+}
+
+func (s *VariantsService) getVariant(code, sku string) (*models.Product, *models.Variant, error) {
+	return getVariant(s.products, s.variants, code, sku)
+}
+
+// getVariant loads a variant by sku and makes sure it belongs to the product
+func getVariant(products ProductsReader, variants VariantsStore, code, sku string) (*models.Product, *models.Variant, error) {
+	product, err := getProduct(products, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variant, err := variants.GetVariantBySKU(sku)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, nil, ErrVariantNotFound
+		}
+		return nil, nil, err
+	}
+
+	if variant.ProductID != product.ID {
+		return nil, nil, ErrVariantNotFound
+	}
+
+	return product, variant, nil
+}
+
+// buildAttributes resolves option names and values, case-insensitively,
+// against the options of the product
+func buildAttributes(product *models.Product, attributes map[string]string) ([]models.VariantAttribute, error) {
+	var result []models.VariantAttribute
+
+	for name, value := range attributes {
+		idx := slices.IndexFunc(product.Options, func(o models.ProductOption) bool {
+			return strings.EqualFold(o.Name, strings.TrimSpace(name))
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: unknown option %s", ErrInvalidAttribute, name)
+		}
+		option := &product.Options[idx]
+
+		// Names match case-insensitively, so Size and size are the same option
+		if slices.ContainsFunc(result, func(a models.VariantAttribute) bool { return a.OptionID == option.ID }) {
+			return nil, fmt.Errorf("%w: %s is given twice", ErrInvalidAttribute, option.Name)
+		}
+
+		idx = slices.IndexFunc(option.Values, func(v models.ProductOptionValue) bool {
+			return strings.EqualFold(v.Value, strings.TrimSpace(value))
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %s is no %s", ErrInvalidAttribute, value, option.Name)
+		}
+
+		result = append(result, models.VariantAttribute{
+			OptionID:      option.ID,
+			Option:        option,
+			OptionValueID: option.Values[idx].ID,
+			OptionValue:   &option.Values[idx],
+		})
+	}
+
+	return result, nil
+}
+
+func (s *VariantsService) validateRequest(req VariantRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return ErrVariantNameRequired
+	}
+
+	if strings.TrimSpace(req.SKU) == "" {
+		return ErrVariantSKURequired
+	}
+
+	if len(req.Name) > 256 {
+		return ErrVariantNameTooLong
+	}
+
+	if len(req.SKU) > 32 {
+		return ErrVariantSKUTooLong
+	}
+
+	if req.Price.Valid {
+		if err := validatePrice(req.Price.Decimal); err != nil {
+			return ErrVariantPriceInvalid
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
 )
 
 type CategoriesHandler struct {
@@ -19,7 +20,15 @@ func NewCategoriesHandler(service *CategoriesService) *CategoriesHandler {
 }
 
 func (h *CategoriesHandler) HandleList(w http.ResponseWriter, r *http.Request) {
-	response, err := h.service.ListCategories()
+	tree := utils.ParseBoolParam(r.URL.Query().Get("tree"), false)
+
+	locales, ok := utils.ParseLocales(r)
+	if !ok {
+		api.ErrorResponse(w, http.StatusBadRequest, ErrInvalidLocale.Error())
+		return
+	}
+
+	response, err := h.service.ListCategories(tree, locales)
 	if err != nil {
 		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -35,18 +44,111 @@ func (h *CategoriesHandler) HandleCreate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response, err := h.service.CreateCategory(req)
+	response, err := h.service.CreateCategory(api.ActorFromRequest(r), req)
 	if err != nil {
-		if errors.Is(err, ErrCategoryCodeRequired) ||
-			errors.Is(err, ErrCategoryNameRequired) ||
-			errors.Is(err, ErrCategoryCodeTooLong) ||
-			errors.Is(err, ErrCategoryNameTooLong) {
-			api.ErrorResponse(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	api.CreatedResponse(w, response)
 }
+
+func (h *CategoriesHandler) HandleGetByCode(w http.ResponseWriter, r *http.Request) {
+	locales, ok := utils.ParseLocales(r)
+	if !ok {
+		api.ErrorResponse(w, http.StatusBadRequest, ErrInvalidLocale.Error())
+		return
+	}
+
+	response, err := h.service.GetCategory(r.PathValue("code"), locales)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CategoriesHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.UpdateCategory(api.ActorFromRequest(r), r.PathValue("code"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CategoriesHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteCategory(api.ActorFromRequest(r), r.PathValue("code")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CategoriesHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	response, err := h.service.RestoreCategory(api.ActorFromRequest(r), r.PathValue("code"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CategoriesHandler) HandleSaveTranslation(w http.ResponseWriter, r *http.Request) {
+	var req TranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.SaveTranslation(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("locale"), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *CategoriesHandler) HandleDeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTranslation(api.ActorFromRequest(r), r.PathValue("code"), r.PathValue("locale")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeServiceError maps the categories service errors to HTTP status codes
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrCategoryCodeRequired),
+		errors.Is(err, ErrCategoryNameRequired),
+		errors.Is(err, ErrCategoryCodeTooLong),
+		errors.Is(err, ErrCategoryNameTooLong),
+		errors.Is(err, ErrParentNotFound),
+		errors.Is(err, ErrCategoryCycle),
+		errors.Is(err, ErrInvalidLocale),
+		errors.Is(err, ErrTaxClassInvalid):
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCategoryNotFound),
+		errors.Is(err, ErrTranslationNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrCategoryExists),
+		errors.Is(err, ErrCategoryInUse),
+		errors.Is(err, ErrParentDeleted):
+		api.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -15,8 +15,17 @@ import (
 )
 
 type mockCategoriesRepo struct {
-	getAllFn func() ([]models.Category, error)
-	createFn func(*models.Category) error
+	getAllFn    func() ([]models.Category, error)
+	getByCodeFn func(string) (*models.Category, error)
+	createFn    func(*models.Category) error
+	updateFn    func(*models.Category) error
+	deleteFn    func(*models.Category) error
+	restoreFn   func(string) error
+	saveTransFn func(*models.CategoryTranslation) error
+	deleteTrFn  func(uint, string) error
+
+	// entries collects the audit entries passed to the writes
+	entries []*models.AuditEntry
 }
 
 func (m *mockCategoriesRepo) GetAllCategories() ([]models.Category, error) {
@@ -26,13 +35,68 @@ func (m *mockCategoriesRepo) GetAllCategories() ([]models.Category, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *mockCategoriesRepo) CreateCategory(category *models.Category) error {
+func (m *mockCategoriesRepo) GetCategoryByCode(code string) (*models.Category, error) {
+	if m.getByCodeFn != nil {
+		return m.getByCodeFn(code)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCategoriesRepo) CreateCategory(category *models.Category, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
 	if m.createFn != nil {
 		return m.createFn(category)
 	}
 	return errors.New("not implemented")
 }
 
+func (m *mockCategoriesRepo) UpdateCategory(category *models.Category, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.updateFn != nil {
+		return m.updateFn(category)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockCategoriesRepo) DeleteCategory(category *models.Category, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteFn != nil {
+		return m.deleteFn(category)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockCategoriesRepo) RestoreCategory(code string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.restoreFn != nil {
+		return m.restoreFn(code)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockCategoriesRepo) SaveCategoryTranslation(translation *models.CategoryTranslation, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.saveTransFn != nil {
+		return m.saveTransFn(translation)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockCategoriesRepo) DeleteCategoryTranslation(categoryID uint, locale string, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	if m.deleteTrFn != nil {
+		return m.deleteTrFn(categoryID, locale)
+	}
+	return errors.New("not implemented")
+}
+
+func findClothing(code string) (*models.Category, error) {
+	if code == "CLOTHING" {
+		return &models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
 func TestHandleList_Success(t *testing.T) {
 	categories := []models.Category{
 		{ID: 1, Code: "CLOTHING", Name: "Clothing"},
@@ -67,6 +131,53 @@ func TestHandleList_Success(t *testing.T) {
 	assert.Equal(t, "Accessories", resp.Categories[2].Name)
 }
 
+func TestHandleList_Tree(t *testing.T) {
+	clothing, dresses := uint(1), uint(3)
+	repo := &mockCategoriesRepo{
+		getAllFn: func() ([]models.Category, error) {
+			return []models.Category{
+				{ID: 1, Code: "CLOTHING", Name: "Clothing"},
+				{ID: 2, Code: "SHOES", Name: "Shoes"},
+				{ID: 3, Code: "DRESSES", Name: "Dresses", ParentID: &clothing},
+				{ID: 4, Code: "MAXI_DRESSES", Name: "Maxi Dresses", ParentID: &dresses},
+			}, nil
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+
+	t.Run("flat list references parents", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleList(w, httptest.NewRequest("GET", "/categories", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp CategoriesListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Categories, 4)
+		assert.Empty(t, resp.Categories[0].Parent)
+		assert.Equal(t, "CLOTHING", resp.Categories[2].Parent)
+		assert.Equal(t, "DRESSES", resp.Categories[3].Parent)
+	})
+
+	t.Run("tree nests children", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleList(w, httptest.NewRequest("GET", "/categories?tree=true", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp CategoriesListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Categories, 2)
+		assert.Equal(t, "CLOTHING", resp.Categories[0].Code)
+		require.Len(t, resp.Categories[0].Children, 1)
+		assert.Equal(t, "DRESSES", resp.Categories[0].Children[0].Code)
+		require.Len(t, resp.Categories[0].Children[0].Children, 1)
+		assert.Equal(t, "MAXI_DRESSES", resp.Categories[0].Children[0].Children[0].Code)
+		assert.Empty(t, resp.Categories[1].Children)
+	})
+}
+
 func TestHandleList_EmptyList(t *testing.T) {
 	repo := &mockCategoriesRepo{
 		getAllFn: func() ([]models.Category, error) {
@@ -267,3 +378,426 @@ func TestHandleCreate_DatabaseError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestHandleCreate_Duplicate(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		createFn: func(category *models.Category) error {
+			return models.ErrDuplicateKey
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+	body, _ := json.Marshal(CreateCategoryRequest{Code: "CLOTHING", Name: "Clothing"})
+	req := httptest.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestHandleCreate_WithParent(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		createFn: func(category *models.Category) error {
+			require.NotNil(t, category.ParentID)
+			assert.Equal(t, uint(1), *category.ParentID)
+			return nil
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+	body, _ := json.Marshal(CreateCategoryRequest{Code: "DRESSES", Name: "Dresses", Parent: "clothing"})
+	req := httptest.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp CategoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "CLOTHING", resp.Parent)
+}
+
+func TestHandleCreate_UnknownParent(t *testing.T) {
+	handler := NewCategoriesHandler(NewCategoriesService(&mockCategoriesRepo{getByCodeFn: findClothing}))
+
+	body, _ := json.Marshal(CreateCategoryRequest{Code: "DRESSES", Name: "Dresses", Parent: "UNKNOWN"})
+	req := httptest.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleUpdate_PreventsCycle(t *testing.T) {
+	clothing := uint(1)
+	repo := &mockCategoriesRepo{
+		getByCodeFn: func(code string) (*models.Category, error) {
+			switch code {
+			case "CLOTHING":
+				return &models.Category{ID: 1, Code: "CLOTHING", Name: "Clothing"}, nil
+			case "DRESSES":
+				return &models.Category{ID: 3, Code: "DRESSES", Name: "Dresses", ParentID: &clothing}, nil
+			}
+			return nil, models.ErrNotFound
+		},
+		// The repository finds the cycle while the rows are locked
+		updateFn: func(category *models.Category) error {
+			assert.Equal(t, uint(1), category.ID)
+			return models.ErrCategoryCycle
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+
+	for _, parent := range []string{"CLOTHING", "DRESSES"} {
+		body, _ := json.Marshal(UpdateCategoryRequest{Name: "Clothing", Parent: parent})
+		req := httptest.NewRequest("PUT", "/categories/CLOTHING", bytes.NewBuffer(body))
+		req.SetPathValue("code", "CLOTHING")
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdate(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, parent)
+	}
+}
+
+func TestHandleGetByCode(t *testing.T) {
+	handler := NewCategoriesHandler(NewCategoriesService(&mockCategoriesRepo{getByCodeFn: findClothing}))
+
+	t.Run("existing category", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/categories/clothing", nil)
+		req.SetPathValue("code", "clothing")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp CategoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "CLOTHING", resp.Code)
+		assert.Equal(t, "Clothing", resp.Name)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/categories/UNKNOWN", nil)
+		req.SetPathValue("code", "UNKNOWN")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetByCode(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleUpdate_Success(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		updateFn: func(category *models.Category) error {
+			assert.Equal(t, uint(1), category.ID)
+			assert.Equal(t, "APPAREL", category.Code)
+			return nil
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+	body, _ := json.Marshal(UpdateCategoryRequest{Code: "apparel", Name: "Apparel"})
+	req := httptest.NewRequest("PUT", "/categories/CLOTHING", bytes.NewBuffer(body))
+	req.SetPathValue("code", "CLOTHING")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp CategoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "APPAREL", resp.Code)
+	assert.Equal(t, "Apparel", resp.Name)
+}
+
+func TestHandleUpdate_AuditEntry(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		updateFn: func(category *models.Category) error {
+			return nil
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+	body, _ := json.Marshal(UpdateCategoryRequest{Code: "apparel", Name: "Clothing"})
+	req := httptest.NewRequest("PUT", "/categories/CLOTHING", bytes.NewBuffer(body))
+	req.SetPathValue("code", "CLOTHING")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, repo.entries, 1)
+
+	// A rename is logged under the new code, without an X-Actor header the
+	// change is anonymous
+	entry := repo.entries[0]
+	assert.Equal(t, "anonymous", entry.Actor)
+	assert.Equal(t, models.AuditEntityCategory, entry.Entity)
+	assert.Equal(t, "APPAREL", entry.Code)
+	assert.Equal(t, map[string]models.AuditChange{
+		"code": {Before: "CLOTHING", After: "APPAREL"},
+	}, entry.Diff())
+}
+
+func TestHandleUpdate_TaxClass(t *testing.T) {
+	var updated *models.Category
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		updateFn: func(category *models.Category) error {
+			updated = category
+			return nil
+		},
+	}
+
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+	body, _ := json.Marshal(UpdateCategoryRequest{Name: "Clothing", TaxClass: "Reduced"})
+	req := httptest.NewRequest("PUT", "/categories/CLOTHING", bytes.NewBuffer(body))
+	req.SetPathValue("code", "CLOTHING")
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdate(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, updated.TaxClass)
+	assert.Equal(t, models.TaxClassReduced, *updated.TaxClass)
+
+	var resp CategoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.TaxClassReduced, resp.TaxClass)
+
+	require.Len(t, repo.entries, 1)
+	assert.Contains(t, repo.entries[0].Diff(), "tax_class")
+}
+
+func TestHandleUpdate_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		body      string
+		updateErr error
+		status    int
+	}{
+		{"invalid json", "CLOTHING", `invalid json`, nil, http.StatusBadRequest},
+		{"missing name", "CLOTHING", `{"code":"CLOTHING"}`, nil, http.StatusBadRequest},
+		{"unknown tax class", "CLOTHING", `{"name":"Clothing","tax_class":"luxury"}`, nil, http.StatusBadRequest},
+		{"unknown category", "UNKNOWN", `{"name":"Unknown"}`, nil, http.StatusNotFound},
+		{"duplicate code", "CLOTHING", `{"code":"SHOES","name":"Shoes"}`, models.ErrDuplicateKey, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockCategoriesRepo{
+				getByCodeFn: findClothing,
+				updateFn: func(category *models.Category) error {
+					return tt.updateErr
+				},
+			}
+
+			handler := NewCategoriesHandler(NewCategoriesService(repo))
+			req := httptest.NewRequest("PUT", "/categories/"+tt.code, bytes.NewBufferString(tt.body))
+			req.SetPathValue("code", tt.code)
+			w := httptest.NewRecorder()
+
+			handler.HandleUpdate(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		deleteErr error
+		status    int
+	}{
+		{"unused category", "CLOTHING", nil, http.StatusNoContent},
+		{"category with products", "CLOTHING", models.ErrReferenced, http.StatusConflict},
+		{"unknown category", "UNKNOWN", nil, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockCategoriesRepo{
+				getByCodeFn: findClothing,
+				deleteFn: func(category *models.Category) error {
+					return tt.deleteErr
+				},
+			}
+
+			handler := NewCategoriesHandler(NewCategoriesService(repo))
+			req := httptest.NewRequest("DELETE", "/categories/"+tt.code, nil)
+			req.SetPathValue("code", tt.code)
+			w := httptest.NewRecorder()
+
+			handler.HandleDelete(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandleList_Localized(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getAllFn: func() ([]models.Category, error) {
+			return []models.Category{
+				{ID: 1, Code: "CLOTHING", Name: "Clothing", Translations: []models.CategoryTranslation{
+					{CategoryID: 1, Locale: "de", Name: "Kleidung"},
+				}},
+				{ID: 2, Code: "SHOES", Name: "Shoes"},
+			}, nil
+		},
+	}
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+
+	for _, url := range []string{"/categories", "/categories?tree=true"} {
+		t.Run(url, func(t *testing.T) {
+			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("Accept-Language", "de-AT, en;q=0.5")
+			w := httptest.NewRecorder()
+
+			handler.HandleList(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp CategoriesListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Categories, 2)
+			assert.Equal(t, "Kleidung", resp.Categories[0].Name)
+			assert.Equal(t, "de", resp.Categories[0].Locale)
+			assert.Equal(t, "Shoes", resp.Categories[1].Name)
+			assert.Equal(t, "en", resp.Categories[1].Locale)
+		})
+	}
+
+	t.Run("invalid locale", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleList(w, httptest.NewRequest("GET", "/categories?locale=x", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleSaveTranslation(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		saveTransFn: func(translation *models.CategoryTranslation) error {
+			assert.Equal(t, uint(1), translation.CategoryID)
+			return nil
+		},
+	}
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+
+	tests := []struct {
+		name   string
+		code   string
+		locale string
+		body   string
+		status int
+	}{
+		{"valid", "CLOTHING", "FR", `{"name":" Vêtements "}`, http.StatusOK},
+		{"missing name", "CLOTHING", "fr", `{"name":""}`, http.StatusBadRequest},
+		{"invalid locale", "CLOTHING", "f", `{"name":"Vêtements"}`, http.StatusBadRequest},
+		{"unknown category", "UNKNOWN", "fr", `{"name":"Vêtements"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/categories/"+tt.code+"/translations/"+tt.locale, bytes.NewBufferString(tt.body))
+			req.SetPathValue("code", tt.code)
+			req.SetPathValue("locale", tt.locale)
+			w := httptest.NewRecorder()
+
+			handler.HandleSaveTranslation(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"locale":"fr","name":"Vêtements"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleDeleteTranslation(t *testing.T) {
+	repo := &mockCategoriesRepo{
+		getByCodeFn: findClothing,
+		deleteTrFn: func(categoryID uint, locale string) error {
+			if locale == "de" {
+				return nil
+			}
+			return models.ErrNotFound
+		},
+	}
+	handler := NewCategoriesHandler(NewCategoriesService(repo))
+
+	tests := []struct {
+		name   string
+		locale string
+		status int
+	}{
+		{"existing translation", "de", http.StatusNoContent},
+		{"missing translation", "fr", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/categories/CLOTHING/translations/"+tt.locale, nil)
+			req.SetPathValue("code", "CLOTHING")
+			req.SetPathValue("locale", tt.locale)
+			w := httptest.NewRecorder()
+
+			handler.HandleDeleteTranslation(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandleRestore(t *testing.T) {
+	tests := []struct {
+		name       string
+		restoreErr error
+		status     int
+	}{
+		{"deleted category", nil, http.StatusOK},
+		{"no deleted category", models.ErrNotFound, http.StatusNotFound},
+		{"code taken again", models.ErrDuplicateKey, http.StatusConflict},
+		{"parent still deleted", models.ErrParentDeleted, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockCategoriesRepo{
+				getByCodeFn: findClothing,
+				restoreFn: func(code string) error {
+					assert.Equal(t, "CLOTHING", code)
+					return tt.restoreErr
+				},
+			}
+
+			handler := NewCategoriesHandler(NewCategoriesService(repo))
+			req := httptest.NewRequest("POST", "/admin/categories/clothing/restore", nil)
+			req.SetPathValue("code", "clothing")
+			w := httptest.NewRecorder()
+
+			handler.HandleRestore(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"code": "CLOTHING", "name": "Clothing", "locale": "en"}`, w.Body.String())
+			}
+		})
+	}
+}
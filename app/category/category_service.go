@@ -1,8 +1,11 @@
 package category
 
 import (
+	"errors"
+	"slices"
 	"strings"
 
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
 	"github.com/mytheresa/go-hiring-challenge/models"
 )
 
@@ -16,17 +19,37 @@ func NewCategoriesService(repo CategoriesReader) *CategoriesService {
 	}
 }
 
-func (s *CategoriesService) ListCategories() (*CategoriesListResponse, error) {
+// ListCategories returns all categories as a flat list, or nested below their
+// parents when tree is set. Names are shown in the first locale of the chain
+// the category is translated to.
+func (s *CategoriesService) ListCategories(tree bool, locales []string) (*CategoriesListResponse, error) {
 	categories, err := s.repo.GetAllCategories()
 	if err != nil {
 		return nil, err
 	}
 
+	if tree {
+		return &CategoriesListResponse{
+			Categories: buildTree(categories, locales),
+		}, nil
+	}
+
+	codes := make(map[uint]string, len(categories))
+	for _, c := range categories {
+		codes[c.ID] = c.Code
+	}
+
 	categoryDTOs := make([]CategoryResponse, len(categories))
 	for i, c := range categories {
+		name, locale := localizeName(c, locales)
 		categoryDTOs[i] = CategoryResponse{
-			Code: c.Code,
-			Name: c.Name,
+			Code:     c.Code,
+			Name:     name,
+			Locale:   locale,
+			TaxClass: taxClass(c),
+		}
+		if c.ParentID != nil {
+			categoryDTOs[i].Parent = codes[*c.ParentID]
 		}
 	}
 
@@ -35,26 +58,252 @@ func (s *CategoriesService) ListCategories() (*CategoriesListResponse, error) {
 	}, nil
 }
 
-func (s *CategoriesService) CreateCategory(req CreateCategoryRequest) (*CategoryResponse, error) {
+func (s *CategoriesService) CreateCategory(actor models.Actor, req CreateCategoryRequest) (*CategoryResponse, error) {
 	if err := s.validateCreateRequest(req); err != nil {
 		return nil, err
 	}
 
 	category := &models.Category{
-		Code: strings.ToUpper(req.Code),
-		Name: req.Name,
+		Code:     strings.ToUpper(req.Code),
+		Name:     req.Name,
+		TaxClass: parseTaxClass(req.TaxClass),
+	}
+
+	if req.Parent != "" {
+		parent, err := s.getParent(req.Parent)
+		if err != nil {
+			return nil, err
+		}
+		category.ParentID = &parent.ID
+		category.Parent = parent
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionCreate, models.AuditEntityCategory, category.Code)
+	entry.After = categorySnapshot(category)
+
+	if err := s.repo.CreateCategory(category, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrCategoryExists
+		case errors.Is(err, models.ErrCategoryDeleted):
+			return nil, ErrParentNotFound
+		}
+		return nil, err
+	}
+
+	return mapCategoryToResponse(category, nil), nil
+}
+
+func (s *CategoriesService) GetCategory(code string, locales []string) (*CategoryResponse, error) {
+	category, err := s.getCategory(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapCategoryToResponse(category, locales), nil
+}
+
+// UpdateCategory replaces the name, parent and tax class of a category, an
+// empty parent turns it into a root category
+func (s *CategoriesService) UpdateCategory(actor models.Actor, code string, req UpdateCategoryRequest) (*CategoryResponse, error) {
+	if req.Code == "" {
+		req.Code = code
+	}
+
+	if err := s.validateCreateRequest(CreateCategoryRequest(req)); err != nil {
+		return nil, err
+	}
+
+	category, err := s.getCategory(code)
+	if err != nil {
+		return nil, err
+	}
+
+	before := categorySnapshot(category)
+
+	category.ParentID = nil
+	category.Parent = nil
+
+	if req.Parent != "" {
+		parent, err := s.getParent(req.Parent)
+		if err != nil {
+			return nil, err
+		}
+
+		category.ParentID = &parent.ID
+		category.Parent = parent
 	}
 
-	if err := s.repo.CreateCategory(category); err != nil {
+	category.Code = strings.ToUpper(req.Code)
+	category.Name = req.Name
+	category.TaxClass = parseTaxClass(req.TaxClass)
+
+	// A renamed category is logged under its new code, the old one is in the
+	// changes
+	entry := models.NewAuditEntry(actor, models.AuditActionUpdate, models.AuditEntityCategory, category.Code)
+	entry.Before = before
+	entry.After = categorySnapshot(category)
+
+	if err := s.repo.UpdateCategory(category, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrCategoryExists
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrCategoryNotFound
+		case errors.Is(err, models.ErrCategoryDeleted):
+			return nil, ErrParentNotFound
+		case errors.Is(err, models.ErrCategoryCycle):
+			return nil, ErrCategoryCycle
+		}
+		return nil, err
+	}
+
+	return mapCategoryToResponse(category, nil), nil
+}
+
+// DeleteCategory soft-deletes a category. It refuses categories that still
+// have products or child categories, so nothing is left pointing at it.
+func (s *CategoriesService) DeleteCategory(actor models.Actor, code string) error {
+	category, err := s.getCategory(code)
+	if err != nil {
+		return err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionDelete, models.AuditEntityCategory, category.Code)
+	entry.Before = categorySnapshot(category)
+
+	if err := s.repo.DeleteCategory(category, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrReferenced):
+			return ErrCategoryInUse
+		case errors.Is(err, models.ErrNotFound):
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// SaveTranslation creates or replaces the name of a category in one locale.
+// The name in models.DefaultLocale stays on the category itself.
+func (s *CategoriesService) SaveTranslation(actor models.Actor, code, locale string, req TranslationRequest) (*TranslationResponse, error) {
+	locale, ok := utils.NormalizeLocale(locale)
+	if !ok {
+		return nil, ErrInvalidLocale
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrCategoryNameRequired
+	}
+	if len(name) > 256 {
+		return nil, ErrCategoryNameTooLong
+	}
+
+	category, err := s.getCategory(code)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &models.CategoryTranslation{
+		CategoryID: category.ID,
+		Locale:     locale,
+		Name:       name,
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionSaveTranslation, models.AuditEntityCategory, category.Code)
+	if existing := categoryTranslation(category, locale); existing != nil {
+		entry.Before = translationSnapshot(existing)
+	}
+	entry.After = translationSnapshot(translation)
+
+	if err := s.repo.SaveCategoryTranslation(translation, entry); err != nil {
 		return nil, err
 	}
 
-	return &CategoryResponse{
-		Code: category.Code,
-		Name: category.Name,
+	return &TranslationResponse{
+		Locale: translation.Locale,
+		Name:   translation.Name,
 	}, nil
 }
 
+func (s *CategoriesService) DeleteTranslation(actor models.Actor, code, locale string) error {
+	locale, ok := utils.NormalizeLocale(locale)
+	if !ok {
+		return ErrInvalidLocale
+	}
+
+	category, err := s.getCategory(code)
+	if err != nil {
+		return err
+	}
+
+	entry := models.NewAuditEntry(actor, models.AuditActionDeleteTranslation, models.AuditEntityCategory, category.Code)
+	if existing := categoryTranslation(category, locale); existing != nil {
+		entry.Before = translationSnapshot(existing)
+	}
+
+	if err := s.repo.DeleteCategoryTranslation(category.ID, locale, entry); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrTranslationNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RestoreCategory brings back a deleted category below its parent, which has
+// to be restored first when it was deleted too
+func (s *CategoriesService) RestoreCategory(actor models.Actor, code string) (*CategoryResponse, error) {
+	code = strings.ToUpper(code)
+
+	entry := models.NewAuditEntry(actor, models.AuditActionRestore, models.AuditEntityCategory, code)
+	entry.Before = map[string]any{"deleted": true}
+	entry.After = map[string]any{"deleted": false}
+
+	if err := s.repo.RestoreCategory(code, entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrCategoryNotFound
+		case errors.Is(err, models.ErrDuplicateKey):
+			return nil, ErrCategoryExists
+		case errors.Is(err, models.ErrParentDeleted):
+			return nil, ErrParentDeleted
+		}
+		return nil, err
+	}
+
+	category, err := s.getCategory(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapCategoryToResponse(category, nil), nil
+}
+
+func (s *CategoriesService) getCategory(code string) (*models.Category, error) {
+	category, err := s.repo.GetCategoryByCode(strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoriesService) getParent(code string) (*models.Category, error) {
+	parent, err := s.getCategory(code)
+	if errors.Is(err, ErrCategoryNotFound) {
+		return nil, ErrParentNotFound
+	}
+
+	return parent, err
+}
+
 func (s *CategoriesService) validateCreateRequest(req CreateCategoryRequest) error {
 	if strings.TrimSpace(req.Code) == "" {
 		return ErrCategoryCodeRequired
@@ -72,5 +321,122 @@ func (s *CategoriesService) validateCreateRequest(req CreateCategoryRequest) err
 		return ErrCategoryNameTooLong
 	}
 
+	if req.TaxClass != "" && !slices.Contains(models.TaxClasses, strings.ToLower(req.TaxClass)) {
+		return ErrTaxClassInvalid
+	}
+
+	return nil
+}
+
+// parseTaxClass stores an empty tax class as nil, which inherits
+func parseTaxClass(class string) *string {
+	if class == "" {
+		return nil
+	}
+	class = strings.ToLower(class)
+	return &class
+}
+
+// taxClass is the class set on the category itself, empty when it inherits
+func taxClass(c models.Category) string {
+	if c.TaxClass == nil {
+		return ""
+	}
+	return *c.TaxClass
+}
+
+func mapCategoryToResponse(c *models.Category, locales []string) *CategoryResponse {
+	name, locale := localizeName(*c, locales)
+	response := &CategoryResponse{
+		Code:     c.Code,
+		Name:     name,
+		Locale:   locale,
+		TaxClass: taxClass(*c),
+	}
+
+	if c.Parent != nil {
+		response.Parent = c.Parent.Code
+	}
+
+	return response
+}
+
+// buildTree nests categories below their parents and returns the roots.
+// Categories caught in a parent cycle are never reached from a root, so they
+// can't make the recursion loop.
+func buildTree(categories []models.Category, locales []string) []CategoryResponse {
+	children := make(map[uint][]models.Category)
+	for _, c := range categories {
+		var parentID uint
+		if c.ParentID != nil {
+			parentID = *c.ParentID
+		}
+		children[parentID] = append(children[parentID], c)
+	}
+
+	var build func(parentID uint, parentCode string) []CategoryResponse
+	build = func(parentID uint, parentCode string) []CategoryResponse {
+		nodes := make([]CategoryResponse, len(children[parentID]))
+		for i, c := range children[parentID] {
+			name, locale := localizeName(c, locales)
+			nodes[i] = CategoryResponse{
+				Code:     c.Code,
+				Name:     name,
+				Locale:   locale,
+				Parent:   parentCode,
+				TaxClass: taxClass(c),
+				Children: build(c.ID, c.Code),
+			}
+		}
+		return nodes
+	}
+
+	return build(0, "")
+}
+
+// localizeName returns the name of a category in the first locale of the
+// chain it is translated to, falling back to the name in models.DefaultLocale
+func localizeName(c models.Category, locales []string) (string, string) {
+	available := make([]string, len(c.Translations))
+	for i, t := range c.Translations {
+		available[i] = t.Locale
+	}
+
+	if i := utils.MatchLocale(locales, available); i >= 0 {
+		return c.Translations[i].Name, c.Translations[i].Locale
+	}
+
+	return c.Name, models.DefaultLocale
+}
+
+// categorySnapshot is what the audit log compares before and after a write
+func categorySnapshot(c *models.Category) map[string]any {
+	var parent any
+	if c.Parent != nil {
+		parent = c.Parent.Code
+	}
+
+	return map[string]any{
+		"code":      c.Code,
+		"name":      c.Name,
+		"parent":    parent,
+		"tax_class": c.TaxClass,
+	}
+}
+
+// translationSnapshot keys the name by locale, so entries of different
+// locales can be told apart
+func translationSnapshot(t *models.CategoryTranslation) map[string]any {
+	return map[string]any{"name." + t.Locale: t.Name}
+}
+
+// categoryTranslation returns the stored name of a category in exactly that
+// locale, or nil
+func categoryTranslation(c *models.Category, locale string) *models.CategoryTranslation {
+	for i := range c.Translations {
+		if c.Translations[i].Locale == locale {
+			return &c.Translations[i]
+		}
+	}
 	return nil
 }
@@ -11,23 +11,68 @@ var (
 	ErrCategoryNameRequired = errors.New("category name is required")
 	ErrCategoryCodeTooLong  = errors.New("category code must not exceed 32 characters")
 	ErrCategoryNameTooLong  = errors.New("category name must not exceed 256 characters")
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrCategoryExists       = errors.New("category code already exists")
+	ErrCategoryInUse        = errors.New("category is still assigned to products or has child categories")
+	ErrParentNotFound       = errors.New("parent category not found")
+	ErrCategoryCycle        = errors.New("category cannot be placed below itself or one of its descendants")
+	ErrParentDeleted        = errors.New("parent category is deleted, restore it first")
+	ErrInvalidLocale        = errors.New("invalid locale, use a BCP 47 tag like en or de-CH")
+	ErrTranslationNotFound  = errors.New("translation not found")
+	ErrTaxClassInvalid      = errors.New("tax class must be standard, reduced or zero")
 )
 
+// CategoriesReader reads and persists categories, each write records its
+// audit entry in the same transaction
 type CategoriesReader interface {
 	GetAllCategories() ([]models.Category, error)
-	CreateCategory(category *models.Category) error
+	GetCategoryByCode(code string) (*models.Category, error)
+	CreateCategory(category *models.Category, entry *models.AuditEntry) error
+	UpdateCategory(category *models.Category, entry *models.AuditEntry) error
+	DeleteCategory(category *models.Category, entry *models.AuditEntry) error
+	RestoreCategory(code string, entry *models.AuditEntry) error
+	SaveCategoryTranslation(translation *models.CategoryTranslation, entry *models.AuditEntry) error
+	DeleteCategoryTranslation(categoryID uint, locale string, entry *models.AuditEntry) error
 }
 
 type CategoryResponse struct {
-	Code string `json:"code"`
-	Name string `json:"name"`
+	Code     string             `json:"code"`
+	Name     string             `json:"name"`
+	Locale   string             `json:"locale,omitempty"`
+	Parent   string             `json:"parent,omitempty"`
+	TaxClass string             `json:"tax_class,omitempty"`
+	Children []CategoryResponse `json:"children,omitempty"`
 }
 
 type CategoriesListResponse struct {
 	Categories []CategoryResponse `json:"categories"`
 }
 
+// CreateCategoryRequest creates a category. An empty tax class inherits the
+// one of the parent, a root category without one is standard.
 type CreateCategoryRequest struct {
-	Code string `json:"code"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Parent   string `json:"parent,omitempty"`
+	TaxClass string `json:"tax_class,omitempty"`
+}
+
+// UpdateCategoryRequest replaces the name, parent and tax class of a
+// category and optionally renames its code, an empty code keeps the current
+// one
+type UpdateCategoryRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Parent   string `json:"parent,omitempty"`
+	TaxClass string `json:"tax_class,omitempty"`
+}
+
+// TranslationRequest sets the name of a category in one locale
+type TranslationRequest struct {
 	Name string `json:"name"`
 }
+
+type TranslationResponse struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
@@ -12,7 +12,10 @@ import (
 func New(user, password, dbname, port string) (db *gorm.DB, close func() error) {
 	dsn := fmt.Sprintf("postgres://%s:%s@localhost:%s/%s?sslmode=disable", user, password, port, dbname)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		// Surface constraint violations as gorm.ErrDuplicatedKey and friends
+		TranslateError: true,
+	})
 	if err != nil {
 		log.Fatalf("failed to connect database: %s", err)
 	}
@@ -0,0 +1,143 @@
+package promotion
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/app/utils"
+)
+
+type PromotionsHandler struct {
+	service *PromotionsService
+}
+
+func NewPromotionsHandler(service *PromotionsService) *PromotionsHandler {
+	return &PromotionsHandler{
+		service: service,
+	}
+}
+
+// HandleList serves GET /promotions?offset=0&limit=50
+func (h *PromotionsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	offset := utils.ParseIntParam(query.Get("offset"), 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	limit := utils.ParseIntParam(query.Get("limit"), 50)
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	response, err := h.service.ListPromotions(offset, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *PromotionsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetPromotion(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *PromotionsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreatePromotion(req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, response)
+}
+
+func (h *PromotionsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.UpdatePromotion(id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+func (h *PromotionsHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePromotion(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil || id == 0 {
+		writeServiceError(w, ErrPromotionIDInvalid)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeServiceError maps the promotions service errors to HTTP status codes
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPromotionIDInvalid),
+		errors.Is(err, ErrNameRequired),
+		errors.Is(err, ErrNameTooLong),
+		errors.Is(err, ErrTypeInvalid),
+		errors.Is(err, ErrValueInvalid),
+		errors.Is(err, ErrPercentInvalid),
+		errors.Is(err, ErrScopeInvalid),
+		errors.Is(err, ErrTargetRequired),
+		errors.Is(err, ErrTargetNotFound),
+		errors.Is(err, ErrWindowInvalid):
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrPromotionNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,274 @@
+package promotion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPromotionsRepo struct {
+	listFn   func(int, int) ([]models.Promotion, int64, error)
+	getFn    func(uint) (*models.Promotion, error)
+	createFn func(*models.Promotion) error
+	updateFn func(*models.Promotion) error
+	deleteFn func(uint) error
+}
+
+func (m *mockPromotionsRepo) GetPromotions(offset, limit int) ([]models.Promotion, int64, error) {
+	if m.listFn != nil {
+		return m.listFn(offset, limit)
+	}
+	return nil, 0, errors.New("not implemented")
+}
+
+func (m *mockPromotionsRepo) GetPromotion(id uint) (*models.Promotion, error) {
+	if m.getFn != nil {
+		return m.getFn(id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPromotionsRepo) CreatePromotion(promotion *models.Promotion) error {
+	if m.createFn != nil {
+		return m.createFn(promotion)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockPromotionsRepo) UpdatePromotion(promotion *models.Promotion) error {
+	if m.updateFn != nil {
+		return m.updateFn(promotion)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockPromotionsRepo) DeletePromotion(id uint) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(id)
+	}
+	return errors.New("not implemented")
+}
+
+// mockTargets knows the category CLOTHING, the product PROD001 and the
+// variant SKU001-R
+type mockTargets struct{}
+
+func (m *mockTargets) GetCategoryByCode(code string) (*models.Category, error) {
+	if code == "CLOTHING" {
+		return &models.Category{ID: 1, Code: code}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *mockTargets) GetProductByCode(code string) (*models.Product, error) {
+	if code == "PROD001" {
+		return &models.Product{ID: 1, Code: code}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *mockTargets) GetVariantBySKU(sku string) (*models.Variant, error) {
+	if sku == "SKU001-R" {
+		return &models.Variant{ID: 1, SKU: sku}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+var testNow = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestHandler(repo *mockPromotionsRepo) *PromotionsHandler {
+	targets := &mockTargets{}
+	service := NewPromotionsService(repo, targets, targets, targets)
+	service.now = func() time.Time { return testNow }
+	return NewPromotionsHandler(service)
+}
+
+func newIDRequest(method, path, id string, body []byte) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.SetPathValue("id", id)
+	return req
+}
+
+func TestHandleCreate_Success(t *testing.T) {
+	var created *models.Promotion
+	repo := &mockPromotionsRepo{
+		createFn: func(p *models.Promotion) error {
+			p.ID = 7
+			created = p
+			return nil
+		},
+	}
+
+	body := []byte(`{"name": " Summer sale ", "type": "PERCENT_OFF", "value": 20, "scope": "category",
+		"target": "clothing", "starts_at": "2026-05-01T00:00:00Z", "ends_at": "2026-07-01T00:00:00Z", "priority": 5}`)
+	w := httptest.NewRecorder()
+	newTestHandler(repo).HandleCreate(w, httptest.NewRequest("POST", "/promotions", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, "Summer sale", created.Name)
+	assert.Equal(t, models.PromotionPercentOff, created.Type)
+	assert.Equal(t, "CLOTHING", created.Target)
+	assert.Equal(t, 5, created.Priority)
+
+	var resp PromotionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, uint(7), resp.ID)
+	assert.Equal(t, "20", resp.Value.String())
+	assert.True(t, resp.Active)
+}
+
+func TestHandleCreate_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{"missing name", `{"type": "percent_off", "value": 10, "scope": "product", "target": "PROD001"}`, ErrNameRequired},
+		{"unknown type", `{"name": "x", "type": "bogo", "value": 10, "scope": "product", "target": "PROD001"}`, ErrTypeInvalid},
+		{"zero discount", `{"name": "x", "type": "fixed_off", "value": 0, "scope": "product", "target": "PROD001"}`, ErrValueInvalid},
+		{"negative fixed price", `{"name": "x", "type": "fixed_price", "value": -1, "scope": "product", "target": "PROD001"}`, ErrValueInvalid},
+		{"sub-cent value", `{"name": "x", "type": "fixed_off", "value": 1.005, "scope": "product", "target": "PROD001"}`, ErrValueInvalid},
+		{"percent over 100", `{"name": "x", "type": "percent_off", "value": 120, "scope": "product", "target": "PROD001"}`, ErrPercentInvalid},
+		{"unknown scope", `{"name": "x", "type": "percent_off", "value": 10, "scope": "brand", "target": "ACME"}`, ErrScopeInvalid},
+		{"missing target", `{"name": "x", "type": "percent_off", "value": 10, "scope": "sku"}`, ErrTargetRequired},
+		{"unknown target", `{"name": "x", "type": "percent_off", "value": 10, "scope": "sku", "target": "sku001-r"}`, ErrTargetNotFound},
+		{"empty window", `{"name": "x", "type": "percent_off", "value": 10, "scope": "product", "target": "PROD001",
+			"starts_at": "2026-07-01T00:00:00Z", "ends_at": "2026-07-01T00:00:00Z"}`, ErrWindowInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockPromotionsRepo{
+				createFn: func(p *models.Promotion) error {
+					t.Fatal("an invalid promotion must not be stored")
+					return nil
+				},
+			}
+
+			w := httptest.NewRecorder()
+			newTestHandler(repo).HandleCreate(w, httptest.NewRequest("POST", "/promotions", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.err.Error())
+		})
+	}
+}
+
+func TestHandleUpdate(t *testing.T) {
+	ends := testNow.Add(-time.Hour)
+	stored := &models.Promotion{ID: 3, Name: "Old", Type: models.PromotionFixedOff, Value: decimal.NewFromInt(5), Scope: models.PromotionScopeProduct, Target: "PROD001", EndsAt: &ends}
+
+	var updated *models.Promotion
+	repo := &mockPromotionsRepo{
+		getFn: func(id uint) (*models.Promotion, error) {
+			if id == 3 {
+				return stored, nil
+			}
+			return nil, models.ErrNotFound
+		},
+		updateFn: func(p *models.Promotion) error {
+			updated = p
+			return nil
+		},
+	}
+	handler := newTestHandler(repo)
+
+	body := []byte(`{"name": "Red price", "type": "fixed_price", "value": "49.90", "scope": "sku", "target": "SKU001-R"}`)
+	w := httptest.NewRecorder()
+	handler.HandleUpdate(w, newIDRequest("PUT", "/promotions/3", "3", body))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, updated)
+	assert.Equal(t, "SKU001-R", updated.Target)
+	// A replace opens the window again when ends_at isn't sent
+	assert.Nil(t, updated.EndsAt)
+
+	var resp PromotionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.PromotionFixedPrice, resp.Type)
+	assert.True(t, resp.Active)
+
+	t.Run("not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleUpdate(w, newIDRequest("PUT", "/promotions/4", "4", body))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleUpdate(w, newIDRequest("PUT", "/promotions/abc", "abc", body))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleList(t *testing.T) {
+	starts := testNow.Add(24 * time.Hour)
+	repo := &mockPromotionsRepo{
+		listFn: func(offset, limit int) ([]models.Promotion, int64, error) {
+			assert.Equal(t, 0, offset)
+			assert.Equal(t, 200, limit)
+			return []models.Promotion{
+				{ID: 2, Name: "Upcoming", Type: models.PromotionPercentOff, Value: decimal.NewFromInt(30), Scope: models.PromotionScopeCategory, Target: "CLOTHING", StartsAt: &starts, Priority: 9},
+				{ID: 1, Name: "Running", Type: models.PromotionFixedOff, Value: decimal.NewFromInt(5), Scope: models.PromotionScopeProduct, Target: "PROD001"},
+			}, 2, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestHandler(repo).HandleList(w, httptest.NewRequest("GET", "/promotions?limit=500", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp PromotionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(2), resp.Total)
+	require.Len(t, resp.Promotions, 2)
+	assert.False(t, resp.Promotions[0].Active)
+	assert.True(t, resp.Promotions[1].Active)
+}
+
+func TestHandleGet(t *testing.T) {
+	repo := &mockPromotionsRepo{
+		getFn: func(id uint) (*models.Promotion, error) {
+			return nil, models.ErrNotFound
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestHandler(repo).HandleGet(w, newIDRequest("GET", "/promotions/9", "9", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleDelete(t *testing.T) {
+	var deleted uint
+	repo := &mockPromotionsRepo{
+		deleteFn: func(id uint) error {
+			if id != 3 {
+				return models.ErrNotFound
+			}
+			deleted = id
+			return nil
+		},
+	}
+	handler := newTestHandler(repo)
+
+	w := httptest.NewRecorder()
+	handler.HandleDelete(w, newIDRequest("DELETE", "/promotions/3", "3", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, uint(3), deleted)
+
+	w = httptest.NewRecorder()
+	handler.HandleDelete(w, newIDRequest("DELETE", "/promotions/4", "4", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,229 @@
+package promotion
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+// maxValue is the first value that no longer fits the DECIMAL(10,2) column
+var maxValue = decimal.New(1, 8)
+
+var hundred = decimal.NewFromInt(100)
+
+type PromotionsService struct {
+	repo       PromotionsStore
+	categories CategoriesFinder
+	products   ProductsFinder
+	variants   VariantsFinder
+	now        func() time.Time
+}
+
+func NewPromotionsService(repo PromotionsStore, categories CategoriesFinder, products ProductsFinder, variants VariantsFinder) *PromotionsService {
+	return &PromotionsService{
+		repo:       repo,
+		categories: categories,
+		products:   products,
+		variants:   variants,
+		now:        time.Now,
+	}
+}
+
+func (s *PromotionsService) ListPromotions(offset, limit int) (*PromotionsResponse, error) {
+	promotions, total, err := s.repo.GetPromotions(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]PromotionResponse, len(promotions))
+	for i, p := range promotions {
+		dtos[i] = s.mapPromotion(&p)
+	}
+
+	return &PromotionsResponse{
+		Promotions: dtos,
+		Total:      total,
+		Offset:     offset,
+		Limit:      limit,
+	}, nil
+}
+
+func (s *PromotionsService) GetPromotion(id uint) (*PromotionResponse, error) {
+	promotion, err := s.getPromotion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.mapPromotion(promotion)
+	return &dto, nil
+}
+
+func (s *PromotionsService) CreatePromotion(req PromotionRequest) (*PromotionResponse, error) {
+	promotion := &models.Promotion{}
+	if err := s.apply(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	dto := s.mapPromotion(promotion)
+	return &dto, nil
+}
+
+// UpdatePromotion replaces every field of a promotion
+func (s *PromotionsService) UpdatePromotion(id uint, req PromotionRequest) (*PromotionResponse, error) {
+	promotion, err := s.getPromotion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePromotion(promotion); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, err
+	}
+
+	dto := s.mapPromotion(promotion)
+	return &dto, nil
+}
+
+func (s *PromotionsService) DeletePromotion(id uint) error {
+	if err := s.repo.DeletePromotion(id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrPromotionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PromotionsService) getPromotion(id uint) (*models.Promotion, error) {
+	promotion, err := s.repo.GetPromotion(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// apply validates req and copies it onto promotion. Product and category
+// codes are stored upper case, skus as they were sent.
+func (s *PromotionsService) apply(promotion *models.Promotion, req PromotionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrNameRequired
+	}
+	if len(name) > 128 {
+		return ErrNameTooLong
+	}
+
+	kind := strings.ToLower(strings.TrimSpace(req.Type))
+	if err := validateValue(kind, req.Value); err != nil {
+		return err
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return ErrWindowInvalid
+	}
+
+	scope := strings.ToLower(strings.TrimSpace(req.Scope))
+	target := strings.TrimSpace(req.Target)
+	if scope != models.PromotionScopeSKU {
+		target = strings.ToUpper(target)
+	}
+	if err := s.findTarget(scope, target); err != nil {
+		return err
+	}
+
+	promotion.Name = name
+	promotion.Type = kind
+	promotion.Value = req.Value
+	promotion.Scope = scope
+	promotion.Target = target
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.Priority = req.Priority
+	return nil
+}
+
+// findTarget checks that the target of a scope exists. It may be deleted
+// later on, the promotion then simply matches nothing.
+func (s *PromotionsService) findTarget(scope, target string) error {
+	var find func(string) error
+	switch scope {
+	case models.PromotionScopeCategory:
+		find = func(code string) error { _, err := s.categories.GetCategoryByCode(code); return err }
+	case models.PromotionScopeProduct:
+		find = func(code string) error { _, err := s.products.GetProductByCode(code); return err }
+	case models.PromotionScopeSKU:
+		find = func(sku string) error { _, err := s.variants.GetVariantBySKU(sku); return err }
+	default:
+		return ErrScopeInvalid
+	}
+
+	if target == "" {
+		return ErrTargetRequired
+	}
+
+	err := find(target)
+
+	if errors.Is(err, models.ErrNotFound) {
+		return ErrTargetNotFound
+	}
+	return err
+}
+
+// validateValue checks the value of a promotion type. A fixed price may be
+// zero to give products away, discounts have to take something off.
+func validateValue(kind string, value decimal.Decimal) error {
+	switch kind {
+	case models.PromotionFixedPrice:
+		if value.IsNegative() {
+			return ErrValueInvalid
+		}
+	case models.PromotionPercentOff, models.PromotionFixedOff:
+		if !value.IsPositive() {
+			return ErrValueInvalid
+		}
+	default:
+		return ErrTypeInvalid
+	}
+
+	if value.GreaterThanOrEqual(maxValue) || !value.Equal(value.Round(2)) {
+		return ErrValueInvalid
+	}
+
+	if kind == models.PromotionPercentOff && value.GreaterThan(hundred) {
+		return ErrPercentInvalid
+	}
+
+	return nil
+}
+
+func (s *PromotionsService) mapPromotion(p *models.Promotion) PromotionResponse {
+	now := s.now()
+	return PromotionResponse{
+		ID:       p.ID,
+		Name:     p.Name,
+		Type:     p.Type,
+		Value:    p.Value,
+		Scope:    p.Scope,
+		Target:   p.Target,
+		StartsAt: p.StartsAt,
+		EndsAt:   p.EndsAt,
+		Priority: p.Priority,
+		Active:   (p.StartsAt == nil || !p.StartsAt.After(now)) && (p.EndsAt == nil || p.EndsAt.After(now)),
+	}
+}
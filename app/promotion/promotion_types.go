@@ -0,0 +1,88 @@
+package promotion
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPromotionNotFound  = errors.New("promotion not found")
+	ErrPromotionIDInvalid = errors.New("promotion id must be a positive integer")
+	ErrNameRequired       = errors.New("promotion name is required")
+	ErrNameTooLong        = errors.New("promotion name must not exceed 128 characters")
+	ErrTypeInvalid        = errors.New("promotion type must be percent_off, fixed_off or fixed_price")
+	ErrValueInvalid       = errors.New("promotion value must be a positive decimal with at most 8 integer digits and 2 decimal places")
+	ErrPercentInvalid     = errors.New("percent_off value must not exceed 100")
+	ErrScopeInvalid       = errors.New("promotion scope must be category, product or sku")
+	ErrTargetRequired     = errors.New("promotion target is required")
+	ErrTargetNotFound     = errors.New("promotion target does not exist")
+	ErrWindowInvalid      = errors.New("ends_at must be after starts_at")
+)
+
+// PromotionsStore interface for reading and persisting promotions
+type PromotionsStore interface {
+	GetPromotions(offset, limit int) ([]models.Promotion, int64, error)
+	GetPromotion(id uint) (*models.Promotion, error)
+	CreatePromotion(promotion *models.Promotion) error
+	UpdatePromotion(promotion *models.Promotion) error
+	DeletePromotion(id uint) error
+}
+
+// The finders check that the target of a promotion exists when it's saved
+
+type CategoriesFinder interface {
+	GetCategoryByCode(code string) (*models.Category, error)
+}
+
+type ProductsFinder interface {
+	GetProductByCode(code string) (*models.Product, error)
+}
+
+type VariantsFinder interface {
+	GetVariantBySKU(sku string) (*models.Variant, error)
+}
+
+// PromotionRequest creates or replaces a promotion
+type PromotionRequest struct {
+	Name string `json:"name"`
+	// Type is percent_off, fixed_off or fixed_price
+	Type string `json:"type"`
+	// Value is a percentage for percent_off and a base currency amount for
+	// the other types
+	Value decimal.Decimal `json:"value"`
+	// Scope is category, product or sku, Target the code or sku it applies to
+	Scope  string `json:"scope"`
+	Target string `json:"target"`
+	// StartsAt and EndsAt are the window the promotion runs in, unset sides
+	// are open
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	// Priority decides between promotions matching the same price, the
+	// highest wins
+	Priority int `json:"priority"`
+}
+
+type PromotionResponse struct {
+	ID       uint            `json:"id"`
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Value    decimal.Decimal `json:"value"`
+	Scope    string          `json:"scope"`
+	Target   string          `json:"target"`
+	StartsAt *time.Time      `json:"starts_at,omitempty"`
+	EndsAt   *time.Time      `json:"ends_at,omitempty"`
+	Priority int             `json:"priority"`
+	// Active tells whether the promotion is running right now
+	Active bool `json:"active"`
+}
+
+// PromotionsResponse lists promotions in the order they take precedence
+type PromotionsResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	Total      int64               `json:"total"`
+	Offset     int                 `json:"offset"`
+	Limit      int                 `json:"limit"`
+}
@@ -0,0 +1,84 @@
+package reservation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+)
+
+type ReservationsHandler struct {
+	service *ReservationsService
+}
+
+func NewReservationsHandler(service *ReservationsService) *ReservationsHandler {
+	return &ReservationsHandler{
+		service: service,
+	}
+}
+
+func (h *ReservationsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.CreateReservation(req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.CreatedResponse(w, response)
+}
+
+func (h *ReservationsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	h.handleReservation(w, r, h.service.GetReservation)
+}
+
+func (h *ReservationsHandler) HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	h.handleReservation(w, r, h.service.ConfirmReservation)
+}
+
+func (h *ReservationsHandler) HandleRelease(w http.ResponseWriter, r *http.Request) {
+	h.handleReservation(w, r, h.service.ReleaseReservation)
+}
+
+func (h *ReservationsHandler) handleReservation(w http.ResponseWriter, r *http.Request, action func(uint) (*ReservationResponse, error)) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil || id == 0 {
+		writeServiceError(w, ErrReservationIDInvalid)
+		return
+	}
+
+	response, err := action(uint(id))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	api.SuccessResponse(w, response)
+}
+
+// writeServiceError maps the reservation service errors to HTTP status codes
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrItemsRequired),
+		errors.Is(err, ErrItemSKURequired),
+		errors.Is(err, ErrQuantityInvalid),
+		errors.Is(err, ErrTTLInvalid),
+		errors.Is(err, ErrVariantNotFound),
+		errors.Is(err, ErrReservationIDInvalid):
+		api.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrReservationNotFound):
+		api.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrReservationNotHeld):
+		api.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		api.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,250 @@
+package reservation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReservationsRepo struct {
+	getFn     func(uint) (*models.Reservation, error)
+	createFn  func(*models.Reservation) error
+	confirmFn func(uint, time.Time) error
+	releaseFn func(uint) error
+	expireFn  func(time.Time) (int, error)
+}
+
+func (m *mockReservationsRepo) GetReservation(id uint) (*models.Reservation, error) {
+	if m.getFn != nil {
+		return m.getFn(id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockReservationsRepo) CreateReservation(reservation *models.Reservation) error {
+	if m.createFn != nil {
+		return m.createFn(reservation)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockReservationsRepo) ConfirmReservation(id uint, now time.Time) error {
+	if m.confirmFn != nil {
+		return m.confirmFn(id, now)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockReservationsRepo) ReleaseReservation(id uint) error {
+	if m.releaseFn != nil {
+		return m.releaseFn(id)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockReservationsRepo) ExpireReservations(now time.Time) (int, error) {
+	if m.expireFn != nil {
+		return m.expireFn(now)
+	}
+	return 0, errors.New("not implemented")
+}
+
+// mockVariantsFinder publishes SKU001A and SKU002A, the variants of draft,
+// archived or unpublished products aren't found
+type mockVariantsFinder struct{}
+
+func (m *mockVariantsFinder) GetPublishedVariantBySKU(sku string) (*models.Variant, error) {
+	switch sku {
+	case "SKU001A":
+		return &models.Variant{ID: 1, SKU: "SKU001A"}, nil
+	case "SKU002A":
+		return &models.Variant{ID: 2, SKU: "SKU002A"}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+var testNow = time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+func newTestService(repo *mockReservationsRepo) *ReservationsService {
+	service := NewReservationsService(repo, &mockVariantsFinder{})
+	service.now = func() time.Time { return testNow }
+	return service
+}
+
+func newIDRequest(method, path, id string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req.SetPathValue("id", id)
+	return req
+}
+
+func TestHandleCreate_Success(t *testing.T) {
+	repo := &mockReservationsRepo{
+		createFn: func(r *models.Reservation) error {
+			assert.Equal(t, testNow.Add(10*time.Minute), r.ExpiresAt)
+
+			// Repeated skus are merged into one item
+			require.Len(t, r.Items, 2)
+			assert.Equal(t, uint(1), r.Items[0].VariantID)
+			assert.Equal(t, 3, r.Items[0].Quantity)
+			assert.Equal(t, uint(2), r.Items[1].VariantID)
+
+			// The repository splits items per warehouse
+			variant := r.Items[0].Variant
+			r.ID = 5
+			r.Status = models.ReservationHeld
+			r.Items = []models.ReservationItem{
+				{VariantID: 1, Variant: variant, WarehouseID: 1, Quantity: 2},
+				{VariantID: 1, Variant: variant, WarehouseID: 2, Quantity: 1},
+				r.Items[1],
+			}
+			return nil
+		},
+	}
+
+	handler := NewReservationsHandler(newTestService(repo))
+	body := `{"items": [{"sku": "SKU001A", "quantity": 2}, {"sku": "SKU002A", "quantity": 1}, {"sku": "SKU001A", "quantity": 1}], "ttl_seconds": 600}`
+	req := httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{
+		"id": 5,
+		"status": "held",
+		"expires_at": "2026-01-02T10:10:00Z",
+		"items": [
+			{"sku": "SKU001A", "quantity": 3},
+			{"sku": "SKU002A", "quantity": 1}
+		]
+	}`, w.Body.String())
+}
+
+func TestHandleCreate_DefaultTTL(t *testing.T) {
+	repo := &mockReservationsRepo{
+		createFn: func(r *models.Reservation) error {
+			assert.Equal(t, testNow.Add(DefaultTTL), r.ExpiresAt)
+			return nil
+		},
+	}
+
+	handler := NewReservationsHandler(newTestService(repo))
+	req := httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(`{"items": [{"sku": "SKU001A", "quantity": 1}]}`))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestHandleCreate_Errors(t *testing.T) {
+	repo := &mockReservationsRepo{
+		createFn: func(r *models.Reservation) error {
+			return models.ErrInsufficientStock
+		},
+	}
+
+	handler := NewReservationsHandler(newTestService(repo))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no items", `{"items": []}`, http.StatusBadRequest},
+		{"missing sku", `{"items": [{"quantity": 1}]}`, http.StatusBadRequest},
+		{"zero quantity", `{"items": [{"sku": "SKU001A", "quantity": 0}]}`, http.StatusBadRequest},
+		{"ttl too long", `{"items": [{"sku": "SKU001A", "quantity": 1}], "ttl_seconds": 7200}`, http.StatusBadRequest},
+		{"unknown sku", `{"items": [{"sku": "NOPE", "quantity": 1}]}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"insufficient stock", `{"items": [{"sku": "SKU001A", "quantity": 100}]}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleCreate(w, httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(tt.body)))
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestHandleConfirm(t *testing.T) {
+	confirmed := false
+	repo := &mockReservationsRepo{
+		confirmFn: func(id uint, now time.Time) error {
+			assert.Equal(t, uint(5), id)
+			assert.Equal(t, testNow, now)
+			confirmed = true
+			return nil
+		},
+		getFn: func(id uint) (*models.Reservation, error) {
+			return &models.Reservation{ID: id, Status: models.ReservationConfirmed}, nil
+		},
+	}
+
+	handler := NewReservationsHandler(newTestService(repo))
+	w := httptest.NewRecorder()
+
+	handler.HandleConfirm(w, newIDRequest("POST", "/reservations/5/confirm", "5"))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, confirmed)
+
+	var resp ReservationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.ReservationConfirmed, resp.Status)
+}
+
+func TestHandleRelease_Errors(t *testing.T) {
+	repo := &mockReservationsRepo{
+		releaseFn: func(id uint) error {
+			if id == 5 {
+				return models.ErrNotHeld
+			}
+			return models.ErrNotFound
+		},
+	}
+
+	handler := NewReservationsHandler(newTestService(repo))
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"already released", "5", http.StatusConflict},
+		{"not found", "6", http.StatusNotFound},
+		{"invalid id", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.HandleRelease(w, newIDRequest("POST", "/reservations/"+tt.id+"/release", tt.id))
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestExpireReservations(t *testing.T) {
+	repo := &mockReservationsRepo{
+		expireFn: func(now time.Time) (int, error) {
+			assert.Equal(t, testNow, now)
+			return 2, nil
+		},
+	}
+
+	expired, err := newTestService(repo).ExpireReservations()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, expired)
+}
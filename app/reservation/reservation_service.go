@@ -0,0 +1,196 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+type ReservationsService struct {
+	repo     ReservationsStore
+	variants VariantsFinder
+	now      func() time.Time
+}
+
+func NewReservationsService(repo ReservationsStore, variants VariantsFinder) *ReservationsService {
+	return &ReservationsService{
+		repo:     repo,
+		variants: variants,
+		now:      time.Now,
+	}
+}
+
+// CreateReservation holds the requested quantities until the ttl runs out.
+// It holds everything or nothing, one missing item fails the whole request.
+// Only variants of the public catalog can be held, like they can be quoted.
+func (s *ReservationsService) CreateReservation(req ReservationRequest) (*ReservationResponse, error) {
+	ttl, err := s.validateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same sku may be sent several times, it's held as one item
+	items := make([]models.ReservationItem, 0, len(req.Items))
+	positions := make(map[uint]int)
+
+	for _, item := range req.Items {
+		variant, err := s.variants.GetPublishedVariantBySKU(strings.TrimSpace(item.SKU))
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, ErrVariantNotFound
+			}
+			return nil, err
+		}
+
+		if i, ok := positions[variant.ID]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+
+		positions[variant.ID] = len(items)
+		items = append(items, models.ReservationItem{VariantID: variant.ID, Variant: variant, Quantity: item.Quantity})
+	}
+
+	reservation := &models.Reservation{
+		ExpiresAt: s.now().Add(ttl),
+		Items:     items,
+	}
+
+	if err := s.repo.CreateReservation(reservation); err != nil {
+		if errors.Is(err, models.ErrInsufficientStock) {
+			return nil, ErrInsufficientStock
+		}
+		return nil, err
+	}
+
+	return mapReservationToResponse(reservation), nil
+}
+
+func (s *ReservationsService) GetReservation(id uint) (*ReservationResponse, error) {
+	reservation, err := s.repo.GetReservation(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	return mapReservationToResponse(reservation), nil
+}
+
+// ConfirmReservation keeps the held stock for good, e.g. after the payment
+// went through
+func (s *ReservationsService) ConfirmReservation(id uint) (*ReservationResponse, error) {
+	if err := s.repo.ConfirmReservation(id, s.now()); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	return s.GetReservation(id)
+}
+
+// ReleaseReservation gives the held stock back
+func (s *ReservationsService) ReleaseReservation(id uint) (*ReservationResponse, error) {
+	if err := s.repo.ReleaseReservation(id); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	return s.GetReservation(id)
+}
+
+// ExpireReservations gives back the stock of every held reservation that ran
+// out and returns how many expired
+func (s *ReservationsService) ExpireReservations() (int, error) {
+	return s.repo.ExpireReservations(s.now())
+}
+
+// RunSweeper expires stale reservations every interval until ctx is done.
+// Several server processes can sweep at once, each reservation only expires
+// once.
+func (s *ReservationsService) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireReservations()
+			if err != nil {
+				log.Printf("Expiring reservations failed: %s", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Expired %d reservations", expired)
+			}
+		}
+	}
+}
+
+func (s *ReservationsService) validateRequest(req ReservationRequest) (time.Duration, error) {
+	if len(req.Items) == 0 {
+		return 0, ErrItemsRequired
+	}
+
+	for _, item := range req.Items {
+		if strings.TrimSpace(item.SKU) == "" {
+			return 0, ErrItemSKURequired
+		}
+		if item.Quantity < 1 {
+			return 0, ErrQuantityInvalid
+		}
+	}
+
+	if req.TTLSeconds == 0 {
+		return DefaultTTL, nil
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if ttl < time.Second || ttl > maxTTL {
+		return 0, ErrTTLInvalid
+	}
+
+	return ttl, nil
+}
+
+func mapRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return ErrReservationNotFound
+	case errors.Is(err, models.ErrNotHeld):
+		return ErrReservationNotHeld
+	}
+	return err
+}
+
+// mapReservationToResponse sums the per warehouse items back into one item
+// per sku
+func mapReservationToResponse(r *models.Reservation) *ReservationResponse {
+	response := &ReservationResponse{
+		ID:        r.ID,
+		Status:    r.Status,
+		ExpiresAt: r.ExpiresAt,
+		Items:     []ItemResponse{},
+	}
+
+	positions := make(map[uint]int)
+	for _, item := range r.Items {
+		if i, ok := positions[item.VariantID]; ok {
+			response.Items[i].Quantity += item.Quantity
+			continue
+		}
+
+		positions[item.VariantID] = len(response.Items)
+		dto := ItemResponse{Quantity: item.Quantity}
+		if item.Variant != nil {
+			dto.SKU = item.Variant.SKU
+		}
+		response.Items = append(response.Items, dto)
+	}
+
+	return response
+}
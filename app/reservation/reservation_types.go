@@ -0,0 +1,63 @@
+package reservation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+var (
+	ErrItemsRequired        = errors.New("at least one item is required")
+	ErrItemSKURequired      = errors.New("item sku is required")
+	ErrQuantityInvalid      = errors.New("item quantity must be a positive integer")
+	ErrTTLInvalid           = errors.New("ttl_seconds must be between 1 and 3600")
+	ErrVariantNotFound      = errors.New("variant not found")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotHeld   = errors.New("reservation was already confirmed, released or has expired")
+	ErrReservationIDInvalid = errors.New("reservation id must be a positive integer")
+)
+
+// DefaultTTL is used when a reservation request sends no ttl_seconds
+const DefaultTTL = 15 * time.Minute
+
+// maxTTL keeps abandoned checkouts from holding stock for too long
+const maxTTL = time.Hour
+
+type ReservationsStore interface {
+	GetReservation(id uint) (*models.Reservation, error)
+	CreateReservation(reservation *models.Reservation) error
+	ConfirmReservation(id uint, now time.Time) error
+	ReleaseReservation(id uint) error
+	ExpireReservations(now time.Time) (int, error)
+}
+
+// VariantsFinder finds the variants that can be reserved, those of the public
+// catalog only
+type VariantsFinder interface {
+	GetPublishedVariantBySKU(sku string) (*models.Variant, error)
+}
+
+type ReservationRequest struct {
+	Items []ItemRequest `json:"items"`
+	// TTLSeconds is how long the stock is held, DefaultTTL when empty
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+type ItemRequest struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type ReservationResponse struct {
+	ID        uint           `json:"id"`
+	Status    string         `json:"status"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Items     []ItemResponse `json:"items"`
+}
+
+type ItemResponse struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
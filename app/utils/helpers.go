@@ -1,6 +1,9 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 // parseIntParam parses a string parameter to int, returning defaultValue if parsing fails
 func ParseIntParam(param string, defaultValue int) int {
@@ -13,3 +16,12 @@ func ParseIntParam(param string, defaultValue int) int {
 	}
 	return value
 }
+
+// ParseBoolParam parses a string parameter to bool, returning defaultValue if parsing fails
+func ParseBoolParam(param string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// maxLocales bounds the fallback chain built from a long Accept-Language header
+const maxLocales = 10
+
+// ParseLocales builds the locale fallback chain of a request. The locale
+// query parameter wins over the Accept-Language header. Every locale is
+// followed by its language ("de-CH" falls back to "de") and the chain always
+// ends with models.DefaultLocale. It returns false for a malformed locale
+// parameter, malformed header entries are skipped.
+func ParseLocales(r *http.Request) ([]string, bool) {
+	var requested []string
+
+	if raw := r.URL.Query().Get("locale"); raw != "" {
+		locale, ok := NormalizeLocale(raw)
+		if !ok {
+			return nil, false
+		}
+		requested = append(requested, locale)
+	} else {
+		requested = parseAcceptLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	var chain []string
+	add := func(locale string) {
+		if len(chain) < maxLocales && !slices.Contains(chain, locale) {
+			chain = append(chain, locale)
+		}
+	}
+
+	for _, locale := range requested {
+		add(locale)
+		if language, _, found := strings.Cut(locale, "-"); found {
+			add(language)
+		}
+	}
+	add(models.DefaultLocale)
+
+	return chain, true
+}
+
+// MatchLocale returns the index of the available locale that comes first in
+// the chain, or -1 when none of them is in it
+func MatchLocale(chain, available []string) int {
+	for _, locale := range chain {
+		if i := slices.Index(available, locale); i >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeLocale brings a BCP 47 tag into the form stored in the database,
+// a lower case language and upper case region, e.g. "de-ch" becomes "de-CH"
+func NormalizeLocale(tag string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+
+	if len(parts[0]) < 2 || len(parts[0]) > 3 || !isAlpha(parts[0]) {
+		return "", false
+	}
+	parts[0] = strings.ToLower(parts[0])
+
+	for i, part := range parts[1:] {
+		if len(part) < 2 || len(part) > 8 || !isAlphanumeric(part) {
+			return "", false
+		}
+		if len(part) == 2 {
+			parts[i+1] = strings.ToUpper(part)
+		} else {
+			parts[i+1] = strings.ToLower(part)
+		}
+	}
+
+	locale := strings.Join(parts, "-")
+	return locale, len(locale) <= 16
+}
+
+// parseAcceptLanguage returns the locales of the header ordered by their
+// quality, e.g. "fr;q=0.8, de-CH" gives [de-CH fr]
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var entries []weighted
+	for entry := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		locale, ok := NormalizeLocale(tag)
+		if !ok || quality <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{locale: locale, quality: quality})
+	}
+
+	slices.SortStableFunc(entries, func(a, b weighted) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	locales := make([]string, len(entries))
+	for i, e := range entries {
+		locales[i] = e.locale
+	}
+	return locales
+}
+
+func isAlpha(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') }) < 0
+}
+
+func isAlphanumeric(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) < 0
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/catalog"
+	"github.com/mytheresa/go-hiring-challenge/app/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// import upserts products and variants from a CSV or NDJSON file, or from
+// stdin, and prints the report as JSON. It exits with 1 when a row failed.
+func main() {
+	if err := run(); err != nil {
+		log.Printf("Import failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// run does the import, returning only once the file and the database are
+// closed again
+func run() error {
+	format := flag.String("format", "", "csv or ndjson, defaults to the file extension")
+	dryRun := flag.Bool("dry-run", false, "validate and count the rows without writing anything")
+	batchSize := flag.Int("batch-size", 500, "rows written per transaction")
+	actor := flag.String("actor", "import", "who the audit log records as making the changes")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file]\n\nReads stdin without a file.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	input := io.Reader(os.Stdin)
+	if path := flag.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", path, err)
+		}
+		defer file.Close()
+		input = file
+
+		if *format == "" {
+			*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+			if *format == "jsonl" {
+				*format = catalog.FormatNDJSON
+			}
+		}
+	}
+
+	// Load environment variables from .env file
+	if err := godotenv.Load(".env"); err != nil {
+		return fmt.Errorf("loading .env file: %w", err)
+	}
+
+	// Initialize database connection
+	db, close := database.New(
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PORT"),
+	)
+	defer close()
+
+	service := catalog.NewImportService(models.NewProductsRepository(db), models.NewCategoriesRepository(db))
+
+	// The run id ties the audit entries of one import together, like the
+	// request id of an API call
+	report, err := service.Import(
+		models.Actor{Name: *actor, RequestID: fmt.Sprintf("import-%d", time.Now().Unix())},
+		input,
+		catalog.ImportOptions{Format: *format, DryRun: *dryRun, BatchSize: *batchSize},
+	)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Printf("Writing the report failed: %s", err)
+	}
+
+	log.Printf("Read %d rows: %d products and %d variants created, %d products and %d variants updated, %d rows failed",
+		report.Rows, report.Products.Created, report.Variants.Created, report.Products.Updated, report.Variants.Updated, len(report.Errors))
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d rows failed", len(report.Errors))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/mytheresa/go-hiring-challenge/app/database"
+	"github.com/mytheresa/go-hiring-challenge/models"
+)
+
+// purge permanently removes products, variants and categories that were
+// soft-deleted more than the given number of days ago
+func main() {
+	days := flag.Int("days", 30, "remove rows soft-deleted more than this many days ago")
+	flag.Parse()
+
+	if *days < 0 {
+		log.Fatalf("days must not be negative, got %d", *days)
+	}
+
+	// Load environment variables from .env file
+	if err := godotenv.Load(".env"); err != nil {
+		log.Fatalf("Error loading .env file: %s", err)
+	}
+
+	// Initialize database connection
+	db, close := database.New(
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_PORT"),
+	)
+	defer close()
+
+	before := time.Now().AddDate(0, 0, -*days)
+
+	result, err := models.NewPurgeRepository(db).PurgeDeleted(before)
+	if err != nil {
+		close()
+		log.Fatalf("Purging rows deleted before %s failed: %s", before.Format(time.RFC3339), err)
+	}
+
+	log.Printf("Purged %d products, %d variants and %d categories deleted before %s",
+		result.Products, result.Variants, result.Categories, before.Format(time.RFC3339))
+}
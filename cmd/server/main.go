@@ -8,11 +8,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/mytheresa/go-hiring-challenge/app/api"
+	"github.com/mytheresa/go-hiring-challenge/app/audit"
 	"github.com/mytheresa/go-hiring-challenge/app/catalog"
 	"github.com/mytheresa/go-hiring-challenge/app/category"
 	"github.com/mytheresa/go-hiring-challenge/app/database"
+	"github.com/mytheresa/go-hiring-challenge/app/promotion"
+	"github.com/mytheresa/go-hiring-challenge/app/reservation"
 	"github.com/mytheresa/go-hiring-challenge/models"
 )
 
@@ -35,31 +40,106 @@ func main() {
 	)
 	defer close()
 
+	// Tax rates per country are configured locally, not in the database
+	taxRates, err := catalog.LoadTaxRates(os.Getenv("TAX_RATES_FILE"))
+	if err != nil {
+		log.Fatalf("Error loading tax rates: %s", err)
+	}
+
 	// Initialize repositories
 	prodRepo := models.NewProductsRepository(db)
 	catRepo := models.NewCategoriesRepository(db)
+	variantRepo := models.NewVariantsRepository(db)
+	priceRepo := models.NewPricesRepository(db)
+	stockRepo := models.NewStockRepository(db)
+	reservationsRepo := models.NewReservationsRepository(db)
+	mediaRepo := models.NewMediaRepository(db)
+	auditRepo := models.NewAuditRepository(db)
+	promotionsRepo := models.NewPromotionsRepository(db)
 
 	// Initialize services
-	catalogService := catalog.NewCatalogService(prodRepo)
+	catalogService := catalog.NewCatalogService(prodRepo, catRepo, priceRepo, taxRates)
+	variantsService := catalog.NewVariantsService(prodRepo, variantRepo, priceRepo)
+	stockService := catalog.NewStockService(prodRepo, variantRepo, stockRepo)
+	mediaService := catalog.NewMediaService(prodRepo, mediaRepo)
+	quotesService := catalog.NewQuotesService(prodRepo, priceRepo)
+	importService := catalog.NewImportService(prodRepo, catRepo)
 	categoriesService := category.NewCategoriesService(catRepo)
+	reservationsService := reservation.NewReservationsService(reservationsRepo, variantRepo)
+	auditService := audit.NewAuditService(auditRepo)
+	promotionsService := promotion.NewPromotionsService(promotionsRepo, catRepo, prodRepo, variantRepo)
 
 	// Initialize handlers
 	catalogHandler := catalog.NewCatalogHandler(catalogService)
+	variantsHandler := catalog.NewVariantsHandler(variantsService)
+	stockHandler := catalog.NewStockHandler(stockService)
+	mediaHandler := catalog.NewMediaHandler(mediaService)
+	quotesHandler := catalog.NewQuotesHandler(quotesService)
+	importHandler := catalog.NewImportHandler(importService)
 	categoriesHandler := category.NewCategoriesHandler(categoriesService)
+	reservationsHandler := reservation.NewReservationsHandler(reservationsService)
+	auditHandler := audit.NewAuditHandler(auditService)
+	promotionsHandler := promotion.NewPromotionsHandler(promotionsService)
 
 	// Set up routing
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /catalog", catalogHandler.HandleGet)
 	mux.HandleFunc("GET /catalog/{code}", catalogHandler.HandleGetByCode)
+	mux.HandleFunc("POST /catalog", catalogHandler.HandleCreate)
+	mux.HandleFunc("GET /catalog/export", catalogHandler.HandleExport)
+	mux.HandleFunc("POST /catalog/import", importHandler.HandleImport)
+	mux.HandleFunc("PUT /catalog/{code}", catalogHandler.HandleUpdate)
+	mux.HandleFunc("PATCH /catalog/{code}", catalogHandler.HandlePatch)
+	mux.HandleFunc("DELETE /catalog/{code}", catalogHandler.HandleDelete)
+	mux.HandleFunc("PUT /catalog/{code}/options", catalogHandler.HandleReplaceOptions)
+	mux.HandleFunc("PUT /catalog/{code}/translations/{locale}", catalogHandler.HandleSaveTranslation)
+	mux.HandleFunc("DELETE /catalog/{code}/translations/{locale}", catalogHandler.HandleDeleteTranslation)
+	mux.HandleFunc("GET /catalog/{code}/price-history", catalogHandler.HandlePriceHistory)
+	mux.HandleFunc("GET /catalog/{code}/media", mediaHandler.HandleList)
+	mux.HandleFunc("POST /catalog/{code}/media", mediaHandler.HandleCreate)
+	mux.HandleFunc("PUT /catalog/{code}/media/{id}", mediaHandler.HandleUpdate)
+	mux.HandleFunc("DELETE /catalog/{code}/media/{id}", mediaHandler.HandleDelete)
+	mux.HandleFunc("GET /catalog/{code}/variants", variantsHandler.HandleList)
+	mux.HandleFunc("POST /catalog/{code}/variants", variantsHandler.HandleCreate)
+	mux.HandleFunc("PUT /catalog/{code}/variants/{sku}", variantsHandler.HandleUpdate)
+	mux.HandleFunc("DELETE /catalog/{code}/variants/{sku}", variantsHandler.HandleDelete)
+	mux.HandleFunc("GET /catalog/{code}/variants/{sku}/stock", stockHandler.HandleGet)
+	mux.HandleFunc("POST /catalog/{code}/variants/{sku}/stock", stockHandler.HandleAdjust)
+	mux.HandleFunc("GET /admin/catalog", catalogHandler.HandleAdminGet)
+	mux.HandleFunc("GET /admin/catalog/{code}", catalogHandler.HandleAdminGetByCode)
+	mux.HandleFunc("GET /admin/catalog/{code}/price-history", catalogHandler.HandleAdminPriceHistory)
+	mux.HandleFunc("POST /admin/catalog/{code}/restore", catalogHandler.HandleRestore)
+	mux.HandleFunc("POST /admin/catalog/{code}/variants/{sku}/restore", variantsHandler.HandleRestore)
+	mux.HandleFunc("POST /admin/categories/{code}/restore", categoriesHandler.HandleRestore)
 	mux.HandleFunc("GET /categories", categoriesHandler.HandleList)
 	mux.HandleFunc("POST /categories", categoriesHandler.HandleCreate)
+	mux.HandleFunc("GET /categories/{code}", categoriesHandler.HandleGetByCode)
+	mux.HandleFunc("PUT /categories/{code}", categoriesHandler.HandleUpdate)
+	mux.HandleFunc("DELETE /categories/{code}", categoriesHandler.HandleDelete)
+	mux.HandleFunc("PUT /categories/{code}/translations/{locale}", categoriesHandler.HandleSaveTranslation)
+	mux.HandleFunc("DELETE /categories/{code}/translations/{locale}", categoriesHandler.HandleDeleteTranslation)
+	mux.HandleFunc("POST /reservations", reservationsHandler.HandleCreate)
+	mux.HandleFunc("GET /reservations/{id}", reservationsHandler.HandleGet)
+	mux.HandleFunc("POST /reservations/{id}/confirm", reservationsHandler.HandleConfirm)
+	mux.HandleFunc("POST /reservations/{id}/release", reservationsHandler.HandleRelease)
+	mux.HandleFunc("POST /quotes", quotesHandler.HandleCreate)
+	mux.HandleFunc("GET /audit", auditHandler.HandleList)
+	mux.HandleFunc("GET /promotions", promotionsHandler.HandleList)
+	mux.HandleFunc("POST /promotions", promotionsHandler.HandleCreate)
+	mux.HandleFunc("GET /promotions/{id}", promotionsHandler.HandleGet)
+	mux.HandleFunc("PUT /promotions/{id}", promotionsHandler.HandleUpdate)
+	mux.HandleFunc("DELETE /promotions/{id}", promotionsHandler.HandleDelete)
 
-	// Set up the HTTP server
+	// Set up the HTTP server, every request gets an id that writes record in
+	// the audit log
 	srv := &http.Server{
 		Addr:    fmt.Sprintf("localhost:%s", os.Getenv("HTTP_PORT")),
-		Handler: mux,
+		Handler: api.WithRequestID(mux),
 	}
 
+	// Give back the stock of reservations nobody confirmed or released
+	go reservationsService.RunSweeper(ctx, time.Minute)
+
 	// Start the server
 	go func() {
 		log.Printf("Starting server on http://%s", srv.Addr)
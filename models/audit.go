@@ -0,0 +1,119 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audited entities, the code of an entry is the product code, variant sku
+// or category code
+const (
+	AuditEntityProduct  = "product"
+	AuditEntityVariant  = "variant"
+	AuditEntityCategory = "category"
+)
+
+// Audited actions
+const (
+	AuditActionCreate            = "create"
+	AuditActionUpdate            = "update"
+	AuditActionDelete            = "delete"
+	AuditActionRestore           = "restore"
+	AuditActionReplaceOptions    = "replace_options"
+	AuditActionSaveTranslation   = "save_translation"
+	AuditActionDeleteTranslation = "delete_translation"
+	AuditActionCreateMedia       = "create_media"
+	AuditActionUpdateMedia       = "update_media"
+	AuditActionDeleteMedia       = "delete_media"
+	AuditActionAdjustStock       = "adjust_stock"
+)
+
+// Actor is who makes a change and the request it's made in
+type Actor struct {
+	Name      string
+	RequestID string
+}
+
+// AuditEntry records one write to the catalog. It's inserted in the same
+// transaction as the write, so the log has an entry for every change that
+// was committed and none for changes that were rolled back.
+type AuditEntry struct {
+	ID        uint   `gorm:"primaryKey"`
+	Actor     string `gorm:"not null"`
+	RequestID string `gorm:"not null"`
+	Action    string `gorm:"not null"`
+	Entity    string `gorm:"not null"`
+	Code      string `gorm:"not null"`
+	// Changes holds a JSON object with the before and after value of every
+	// field that changed, it's computed from Before and After on insert
+	Changes   string `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time
+
+	// Before and After are snapshots of the entity, nil when it didn't exist
+	// before or doesn't exist anymore after the write
+	Before map[string]any `gorm:"-"`
+	After  map[string]any `gorm:"-"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// AuditChange is the value of a field before and after a write
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// NewAuditEntry starts the entry of a write made by actor
+func NewAuditEntry(actor Actor, action, entity, code string) *AuditEntry {
+	return &AuditEntry{
+		Actor:     actor.Name,
+		RequestID: actor.RequestID,
+		Action:    action,
+		Entity:    entity,
+		Code:      code,
+	}
+}
+
+// BeforeCreate computes the changes only now, after the audited write ran,
+// so values it fills in (e.g. ids behind pointers) are included
+func (e *AuditEntry) BeforeCreate(tx *gorm.DB) error {
+	changes, err := json.Marshal(e.Diff())
+	if err != nil {
+		return err
+	}
+	e.Changes = string(changes)
+	return nil
+}
+
+// Diff compares the snapshots field by field, values are compared by their
+// JSON encoding since that's how they are stored
+func (e *AuditEntry) Diff() map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+
+	for field, before := range e.Before {
+		after, ok := e.After[field]
+		if ok && sameJSON(before, after) {
+			continue
+		}
+		changes[field] = AuditChange{Before: before, After: after}
+	}
+
+	for field, after := range e.After {
+		if _, ok := e.Before[field]; !ok {
+			changes[field] = AuditChange{After: after}
+		}
+	}
+
+	return changes
+}
+
+func sameJSON(a, b any) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(x, y)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// withAudit runs a write and records its audit entry in one transaction, a
+// nil entry isn't recorded
+func withAudit(db *gorm.DB, entry *AuditEntry, write func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		if entry == nil {
+			return nil
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+// AuditFilter selects the entries of an entity, empty fields match all
+type AuditFilter struct {
+	Entity string
+	Code   string
+}
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// GetAuditEntries returns the matching entries newest first together with
+// their total count
+func (r *AuditRepository) GetAuditEntries(offset, limit int, filter AuditFilter) ([]AuditEntry, int64, error) {
+	query := r.db.Model(&AuditEntry{})
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []AuditEntry
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
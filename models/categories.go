@@ -1,9 +1,21 @@
 package models
 
+import (
+	"gorm.io/gorm"
+)
+
 type Category struct {
 	ID   uint   `gorm:"primaryKey"`
 	Code string `gorm:"uniqueIndex;not null"`
-	Name string `gorm:"not null"`
+	// Name is the name in DefaultLocale, Translations hold the other locales
+	Name     string `gorm:"not null"`
+	ParentID *uint  `gorm:"index"`
+	// TaxClass nil inherits the tax class of the parent, the top level
+	// falls back to TaxClassStandard
+	TaxClass     *string
+	Parent       *Category             `gorm:"foreignKey:ParentID"`
+	Translations []CategoryTranslation `gorm:"foreignKey:CategoryID"`
+	DeletedAt    gorm.DeletedAt        `gorm:"index"`
 }
 
 func (c *Category) TableName() string {
@@ -1,9 +1,22 @@
 package models
 
 import (
+	"errors"
+	"slices"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// categorySubtreeSQL selects the ids of the category with the given code and
+// of all its descendants. UNION (not UNION ALL) stops the recursion even if
+// the data ever contains a cycle. Soft-deleted categories are left out.
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE code = ? AND deleted_at IS NULL
+	UNION
+	SELECT c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id WHERE c.deleted_at IS NULL
+) SELECT id FROM subtree`
+
 type CategoriesRepository struct {
 	db *gorm.DB
 }
@@ -16,12 +29,197 @@ func NewCategoriesRepository(db *gorm.DB) *CategoriesRepository {
 
 func (r *CategoriesRepository) GetAllCategories() ([]Category, error) {
 	var categories []Category
-	if err := r.db.Find(&categories).Error; err != nil {
+	if err := r.db.Preload("Translations").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
-func (r *CategoriesRepository) CreateCategory(category *Category) error {
-	return r.db.Create(category).Error
+func (r *CategoriesRepository) GetCategoryByCode(code string) (*Category, error) {
+	var category Category
+	if err := r.db.Where("code = ?", code).Preload("Parent").Preload("Translations").First(&category).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &category, nil
+}
+
+// lockLiveCategories takes a share lock on the categories with the given ids,
+// nil ids are skipped. DeleteCategory locks the category for update, so a
+// writer assigning a category and the delete wait for each other. It fails
+// with ErrCategoryDeleted when a category was deleted in the meantime.
+func lockLiveCategories(tx *gorm.DB, ids ...*uint) error {
+	var wanted []uint
+	for _, id := range ids {
+		if id != nil && !slices.Contains(wanted, *id) {
+			wanted = append(wanted, *id)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	var locked []uint
+	err := tx.Model(&Category{}).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthShare}).
+		Where("id IN ?", wanted).
+		Pluck("id", &locked).Error
+	if err != nil {
+		return err
+	}
+	if len(locked) < len(wanted) {
+		return ErrCategoryDeleted
+	}
+	return nil
+}
+
+// lockCategory locks the live category with the id for update. It fails with
+// ErrNotFound when there is none.
+func lockCategory(tx *gorm.DB, id uint) error {
+	var ids []uint
+	err := tx.Model(&Category{}).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("id = ?", id).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// lockAncestors walks up from the category with the given id to its root and
+// locks every category on the way for update, so the chain can't change until
+// the transaction ends. It fails with ErrCategoryCycle when the walk reaches
+// the category being moved and with ErrCategoryDeleted when a category on the
+// way is deleted.
+func lockAncestors(tx *gorm.DB, moved uint, id *uint) error {
+	for id != nil {
+		if *id == moved {
+			return ErrCategoryCycle
+		}
+
+		var ancestor Category
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Select("id", "parent_id").
+			Where("id = ?", *id).
+			Take(&ancestor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryDeleted
+		}
+		if err != nil {
+			return err
+		}
+		id = ancestor.ParentID
+	}
+	return nil
+}
+
+func (r *CategoriesRepository) CreateCategory(category *Category, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		if err := lockLiveCategories(tx, category.ParentID); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(category).Error
+	}))
+}
+
+// UpdateCategory writes the code, name, parent and tax class of a category.
+// The category and the ancestors of its new parent are locked before the
+// parent is checked, so two concurrent moves can't create a cycle. It fails
+// with ErrCategoryCycle when the new parent is the category itself or one of
+// its descendants.
+func (r *CategoriesRepository) UpdateCategory(category *Category, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		if err := lockCategory(tx, category.ID); err != nil {
+			return err
+		}
+		if err := lockAncestors(tx, category.ID, category.ParentID); err != nil {
+			return err
+		}
+		result := tx.Model(category).Select("Code", "Name", "ParentID", "TaxClass").Updates(category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+// DeleteCategory soft-deletes a category only while no product and no child
+// category points at it. Soft-deleted products and children don't count. The
+// category row is locked first: writers assigning the category hold a share
+// lock on it (see lockLiveCategories), so the checks run only after they have
+// committed, and writers coming later see the category deleted.
+func (r *CategoriesRepository) DeleteCategory(category *Category, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		if err := lockCategory(tx, category.ID); err != nil {
+			return err
+		}
+
+		result := tx.
+			Where("NOT EXISTS (SELECT 1 FROM products WHERE products.category_id = categories.id AND products.deleted_at IS NULL)").
+			Where("NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id = categories.id AND c.deleted_at IS NULL)").
+			Delete(category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReferenced
+		}
+		return nil
+	}))
+}
+
+// RestoreCategory brings back the most recently deleted category with the
+// code. It fails with ErrParentDeleted while its parent is still deleted and
+// with ErrDuplicateKey when the code was taken again in the meantime.
+func (r *CategoriesRepository) RestoreCategory(code string, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		var category Category
+		err := tx.Unscoped().
+			Where("code = ? AND deleted_at IS NOT NULL", code).
+			Order("deleted_at DESC").
+			First(&category).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Model(&category).
+			Where("(parent_id IS NULL OR EXISTS (SELECT 1 FROM categories p WHERE p.id = categories.parent_id AND p.deleted_at IS NULL))").
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrParentDeleted
+		}
+		return nil
+	}))
+}
+
+// SaveCategoryTranslation creates or replaces the name of a category in a locale
+func (r *CategoriesRepository) SaveCategoryTranslation(translation *CategoryTranslation, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		}).Create(translation).Error
+	}))
+}
+
+func (r *CategoriesRepository) DeleteCategoryTranslation(categoryID uint, locale string, entry *AuditEntry) error {
+	return withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Where("category_id = ? AND locale = ?", categoryID, locale).Delete(&CategoryTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound     = errors.New("record not found")
+	ErrDuplicateKey = errors.New("duplicate key")
+	ErrReferenced   = errors.New("record is still referenced")
+	// ErrInsufficientStock is returned when a decrement would make a stock
+	// level negative
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrNotHeld is returned when a reservation was already confirmed,
+	// released or expired
+	ErrNotHeld = errors.New("reservation is not held")
+	// ErrParentDeleted is returned when restoring a category below a parent
+	// that is still soft-deleted
+	ErrParentDeleted = errors.New("parent is deleted")
+	// ErrCategoryCycle is returned when a category would be placed below
+	// itself or one of its descendants
+	ErrCategoryCycle = errors.New("category cycle")
+	// ErrCategoryDeleted is returned when a product or category is assigned a
+	// category that was deleted in the meantime, or a product is restored
+	// while its category is deleted
+	ErrCategoryDeleted = errors.New("category is deleted")
+)
+
+// translateError maps gorm errors to the repository sentinel errors so callers
+// don't need to depend on gorm to tell them apart
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicateKey
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return ErrReferenced
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ProductMedia references an image held in the external media store, this
+// service only keeps its URL and metadata. Media linked to a variant show
+// that variant, the others the product as a whole.
+type ProductMedia struct {
+	ID        uint     `gorm:"primaryKey"`
+	ProductID uint     `gorm:"not null;index"`
+	VariantID *uint    `gorm:"index"`
+	Variant   *Variant `gorm:"foreignKey:VariantID"`
+	URL       string   `gorm:"not null"`
+	AltText   string   `gorm:"not null"`
+	Width     int      `gorm:"not null"`
+	Height    int      `gorm:"not null"`
+	Position  int      `gorm:"not null"`
+}
+
+func (m *ProductMedia) TableName() string {
+	return "product_media"
+}
+
+// primaryMedia only preloads the first image of each product, so listing
+// products doesn't load whole galleries
+func primaryMedia(db *gorm.DB) *gorm.DB {
+	return db.Where(`NOT EXISTS (SELECT 1 FROM product_media m WHERE m.product_id = product_media.product_id
+		AND (m.position, m.id) < (product_media.position, product_media.id))`)
+}
+
+// byPositionAndID orders media with the same position as they were added
+func byPositionAndID(db *gorm.DB) *gorm.DB {
+	return db.Order("position").Order("id")
+}
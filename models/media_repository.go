@@ -0,0 +1,74 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{
+		db: db,
+	}
+}
+
+func (r *MediaRepository) GetProductMedia(productID uint) ([]ProductMedia, error) {
+	var media []ProductMedia
+	err := r.db.Where("product_id = ?", productID).
+		Preload("Variant").
+		Scopes(byPositionAndID).
+		Find(&media).Error
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetMedia loads a media entry of a product, entries of other products are
+// reported as ErrNotFound
+func (r *MediaRepository) GetMedia(productID, id uint) (*ProductMedia, error) {
+	var media ProductMedia
+	err := r.db.Where("product_id = ? AND id = ?", productID, id).
+		Preload("Variant").
+		First(&media).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &media, nil
+}
+
+func (r *MediaRepository) CreateMedia(media *ProductMedia, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		return tx.Omit("Variant").Create(media).Error
+	}))
+}
+
+func (r *MediaRepository) UpdateMedia(media *ProductMedia, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Model(media).
+			Select("VariantID", "URL", "AltText", "Width", "Height", "Position").
+			Updates(media)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+func (r *MediaRepository) DeleteMedia(productID, id uint, entry *AuditEntry) error {
+	return withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Where("product_id = ? AND id = ?", productID, id).Delete(&ProductMedia{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ProductOption is an axis the variants of a product differ on, e.g. Size
+// with the values S, M and L
+type ProductOption struct {
+	ID        uint                 `gorm:"primaryKey"`
+	ProductID uint                 `gorm:"not null"`
+	Name      string               `gorm:"not null"`
+	Position  int                  `gorm:"not null"`
+	Values    []ProductOptionValue `gorm:"foreignKey:OptionID"`
+}
+
+func (o *ProductOption) TableName() string {
+	return "product_options"
+}
+
+type ProductOptionValue struct {
+	ID       uint   `gorm:"primaryKey"`
+	OptionID uint   `gorm:"not null"`
+	Value    string `gorm:"not null"`
+	Position int    `gorm:"not null"`
+}
+
+func (v *ProductOptionValue) TableName() string {
+	return "product_option_values"
+}
+
+// VariantAttribute is the value a variant has for one option of its product
+type VariantAttribute struct {
+	VariantID     uint                `gorm:"primaryKey"`
+	OptionID      uint                `gorm:"primaryKey"`
+	Option        *ProductOption      `gorm:"foreignKey:OptionID"`
+	OptionValueID uint                `gorm:"not null"`
+	OptionValue   *ProductOptionValue `gorm:"foreignKey:OptionValueID"`
+}
+
+func (a *VariantAttribute) TableName() string {
+	return "variant_attributes"
+}
+
+// byPosition orders preloaded options and values as they were defined
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Market is a storefront region that sells in one currency
+type Market struct {
+	ID       uint   `gorm:"primaryKey"`
+	Code     string `gorm:"uniqueIndex;not null"`
+	Currency string `gorm:"not null"`
+}
+
+func (m *Market) TableName() string {
+	return "markets"
+}
+
+// ExchangeRate converts BaseCurrency amounts, Rate is the amount of Currency
+// one unit of BaseCurrency buys
+type ExchangeRate struct {
+	Currency string          `gorm:"primaryKey"`
+	Rate     decimal.Decimal `gorm:"type:decimal(18,8);not null"`
+}
+
+func (e *ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// PriceListEntry is an explicit price of a product, or of one of its variants
+// when VariantID is set, in a currency. Entries without a market apply to
+// every market selling in that currency.
+type PriceListEntry struct {
+	ID        uint            `gorm:"primaryKey"`
+	ProductID uint            `gorm:"not null"`
+	VariantID *uint           `gorm:"index"`
+	Currency  string          `gorm:"not null"`
+	Market    *string         `gorm:""`
+	Amount    decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+}
+
+func (p *PriceListEntry) TableName() string {
+	return "price_list_entries"
+}
+
+// PriceHistoryEntry is the base price of a product, or of one of its variants
+// when SKU is set, in effect from ValidFrom until ValidTo. A nil ValidTo
+// marks the current price. Entries are written by database triggers whenever
+// a price changes. They keep the product code and the sku, ProductID and
+// VariantID are cleared once the product or variant is purged.
+type PriceHistoryEntry struct {
+	ID          uint    `gorm:"primaryKey"`
+	ProductID   *uint   `gorm:"index"`
+	VariantID   *uint   `gorm:"index"`
+	ProductCode string  `gorm:"not null"`
+	SKU         *string `gorm:"column:sku"`
+	// Amount is null while a variant inherited the product price
+	Amount    decimal.NullDecimal `gorm:"type:decimal(10,2)"`
+	ValidFrom time.Time           `gorm:"not null"`
+	ValidTo   *time.Time
+}
+
+func (p *PriceHistoryEntry) TableName() string {
+	return "price_history"
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PricesRepository struct {
+	db *gorm.DB
+}
+
+func NewPricesRepository(db *gorm.DB) *PricesRepository {
+	return &PricesRepository{
+		db: db,
+	}
+}
+
+func (r *PricesRepository) GetMarketByCode(code string) (*Market, error) {
+	var market Market
+	if err := r.db.Where("code = ?", code).First(&market).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &market, nil
+}
+
+func (r *PricesRepository) GetExchangeRate(currency string) (decimal.Decimal, error) {
+	var rate ExchangeRate
+	if err := r.db.Where("currency = ?", currency).First(&rate).Error; err != nil {
+		return decimal.Zero, translateError(err)
+	}
+	return rate.Rate, nil
+}
+
+// GetPriceListEntries returns the entries of the products in the currency
+// that apply to the market, entries without a market come first so callers
+// can let the market specific ones override them
+func (r *PricesRepository) GetPriceListEntries(productIDs []uint, currency, market string) ([]PriceListEntry, error) {
+	var entries []PriceListEntry
+	if len(productIDs) == 0 {
+		return entries, nil
+	}
+
+	err := r.db.
+		Where("product_id IN ? AND currency = ? AND (market IS NULL OR market = ?)", productIDs, currency, market).
+		Order("market NULLS FIRST").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetPriceHistory returns the price periods of a product and its variants,
+// those of deleted and purged variants included, oldest first. Periods that ended in
+// the transaction that started them were never in effect and are left out.
+func (r *PricesRepository) GetPriceHistory(productID uint) ([]PriceHistoryEntry, error) {
+	var entries []PriceHistoryEntry
+	err := r.db.
+		Where("product_id = ? AND (valid_to IS NULL OR valid_to > valid_from)", productID).
+		Order("valid_from, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetPricesAt returns the price periods of a product and its variants that
+// were in effect at the given moment
+func (r *PricesRepository) GetPricesAt(productID uint, at time.Time) ([]PriceHistoryEntry, error) {
+	var entries []PriceHistoryEntry
+	err := r.db.
+		Where("product_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", productID, at, at).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetActivePromotions returns the promotions running now with their targets
+// resolved, in the order they take precedence
+func (r *PricesRepository) GetActivePromotions() ([]Promotion, error) {
+	var promotions []Promotion
+	if err := r.db.Scopes(runningPromotions, byPrecedence).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+
+	if err := resolvePromotionTargets(r.db, promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
@@ -1,16 +1,46 @@
 package models
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// BaseCurrency is the currency of products.price and product_variants.price
+const BaseCurrency = "EUR"
+
+// Product statuses. Only active products inside their publishing window are
+// part of the public catalog.
+const (
+	ProductStatusDraft    = "draft"
+	ProductStatusActive   = "active"
+	ProductStatusArchived = "archived"
 )
 
 type Product struct {
-	ID         uint            `gorm:"primaryKey"`
-	Code       string          `gorm:"uniqueIndex;not null"`
-	Price      decimal.Decimal `gorm:"type:decimal(10,2);not null"`
-	CategoryID *uint           `gorm:"index"`
-	Category   *Category       `gorm:"foreignKey:CategoryID"`
-	Variants   []Variant       `gorm:"foreignKey:ProductID"`
+	ID   uint   `gorm:"primaryKey"`
+	Code string `gorm:"uniqueIndex;not null"`
+	// Brand is shown as is in every locale
+	Brand  string          `gorm:"not null"`
+	Price  decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+	Status string          `gorm:"not null;default:draft"`
+	// PublishFrom and PublishUntil limit when an active product is public,
+	// nil leaves that side of the window open
+	PublishFrom  *time.Time
+	PublishUntil *time.Time
+	// TaxClass nil inherits the tax class of the category
+	TaxClass     *string
+	CategoryID   *uint                `gorm:"index"`
+	Category     *Category            `gorm:"foreignKey:CategoryID"`
+	Variants     []Variant            `gorm:"foreignKey:ProductID"`
+	Options      []ProductOption      `gorm:"foreignKey:ProductID"`
+	Translations []ProductTranslation `gorm:"foreignKey:ProductID"`
+	Media        []ProductMedia       `gorm:"foreignKey:ProductID"`
+	// DeletedAt soft-deletes the product together with its variants
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// SearchRank is only filled when searching, it's never written back
+	SearchRank float64 `gorm:"->;column:search_rank"`
 }
 
 func (p *Product) TableName() string {
@@ -1,9 +1,177 @@
 package models
 
 import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// SortField orders products by one of the ProductSortColumns
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ProductSortColumns whitelists the fields products can be sorted by. The
+// relevance column only exists while searching.
+var ProductSortColumns = map[string]string{
+	"id":        "products.id",
+	"code":      "products.code",
+	"price":     "products.price",
+	"relevance": "search_rank",
+}
+
+// CategoryCount is the number of matching products in one category
+type CategoryCount struct {
+	Code  string
+	Name  string
+	Count int64
+}
+
+// PriceBucket is the number of matching products priced in [Min, Max)
+type PriceBucket struct {
+	Min   decimal.Decimal
+	Max   decimal.Decimal
+	Count int64
+}
+
+// ProductFilter narrows down and orders the products returned by GetProductsWithPagination
+type ProductFilter struct {
+	Category string
+	// IncludeSubcategories also matches products of every descendant of Category
+	IncludeSubcategories bool
+	PriceLessThan        *decimal.Decimal
+	// PriceMin and PriceMax are inclusive bounds
+	PriceMin *decimal.Decimal
+	PriceMax *decimal.Decimal
+	// MatchVariantPrices applies the price bounds to the effective variant
+	// prices instead of products.price, a product matches when any of its
+	// variants does. Products without variants fall back to their own price.
+	MatchVariantPrices bool
+	// InStock only matches products with at least one variant in stock
+	InStock bool
+	// Attributes matches products with at least one variant that has, for
+	// every option name, one of the listed values. Names and values are
+	// compared case-insensitively and must be given in lower case.
+	Attributes map[string][]string
+	// Search is a free-text query matched against codes, brands, titles and
+	// descriptions in every locale, SKUs and variant names
+	Search string
+	// Sort is applied in order, products.id is always added as the last key
+	// so pages are stable
+	Sort []SortField
+	// After continues a keyset page. It holds the sort values of the last
+	// product of the previous page, one per Sort field.
+	After []any
+	// SkipTotal skips the COUNT(*) query, the returned total is then 0
+	SkipTotal bool
+	// IncludeUnpublished also matches drafts, archived products and products
+	// outside their publishing window, Status then narrows them down
+	IncludeUnpublished bool
+	Status             string
+	// Promotions are the running promotions in the order they take
+	// precedence. Price bounds, the price sort and price facets then apply to
+	// the sale price instead of the stored one.
+	Promotions []Promotion
+	// Prices is the currency and market the price bounds are given in, the
+	// price sort and price facets use the same prices
+	Prices PriceView
+}
+
+// PriceView resolves prices in a currency and market the way responses show
+// them: a market price list entry wins over one for the whole currency, and
+// every other price is the base price converted with Rate and rounded to
+// cents. The zero value is the base currency.
+type PriceView struct {
+	Currency string
+	Market   string
+	Rate     decimal.Decimal
+}
+
+// isBase reports whether the stored prices are compared as they are
+func (v PriceView) isBase() bool {
+	return v.Currency == "" || (v.Currency == BaseCurrency && v.Market == "")
+}
+
+// convert turns a base currency price expression into the currency
+func (v PriceView) convert(price string) string {
+	if v.Currency == "" || v.Currency == BaseCurrency {
+		return price
+	}
+	return "ROUND(" + price + " * " + v.Rate.String() + ", 2)"
+}
+
+// convertAmount is convert for an amount known in advance
+func (v PriceView) convertAmount(amount decimal.Decimal) decimal.Decimal {
+	if v.Currency == "" || v.Currency == BaseCurrency {
+		return amount
+	}
+	return amount.Mul(v.Rate).Round(2)
+}
+
+// entryJoins left joins the price list entries of the product "products", or
+// of the variant "v" with variant, as <alias>c for the currency and <alias>m
+// for the market. The unique index allows at most one of each, so the joins
+// never multiply rows.
+func (v PriceView) entryJoins(alias string, variant bool) (string, []any) {
+	if v.isBase() {
+		return "", nil
+	}
+
+	join := func(name, market string) string {
+		owner := name + ".product_id = products.id AND " + name + ".variant_id IS NULL"
+		if variant {
+			owner = name + ".variant_id = v.id"
+		}
+		return " LEFT JOIN price_list_entries " + name + " ON " + owner +
+			" AND " + name + ".currency = ? AND " + name + ".market " + market
+	}
+
+	joins := join(alias+"c", "IS NULL")
+	values := []any{v.Currency}
+	if v.Market != "" {
+		joins += join(alias+"m", "= ?")
+		values = append(values, v.Currency, v.Market)
+	}
+	return joins, values
+}
+
+// entryPrice picks the entry of the joins of entryJoins over fallback
+func (v PriceView) entryPrice(alias, fallback string) string {
+	if v.isBase() {
+		return fallback
+	}
+
+	amounts := alias + "c.amount"
+	if v.Market != "" {
+		amounts = alias + "m.amount, " + amounts
+	}
+	return "COALESCE(" + amounts + ", " + fallback + ")"
+}
+
+// productPriceSQL is the price a product sells for in the currency of the
+// filter, its promotion applied
+func (f ProductFilter) productPriceSQL() string {
+	price := f.Prices.entryPrice("pp", f.Prices.convert("products.price"))
+	return salePriceSQL(price, f.Promotions, false, f.Prices)
+}
+
+// variantPriceSQL is the price the variant "v" sells for in the currency of
+// the filter. A variant without an entry or an own price inherits the
+// resolved price of its product, the promotion applies after that.
+func (f ProductFilter) variantPriceSQL() string {
+	product := f.Prices.entryPrice("pp", f.Prices.convert("products.price"))
+	price := f.Prices.entryPrice("vp", "COALESCE("+f.Prices.convert("NULLIF(v.price, 0)")+", "+product+")")
+	return salePriceSQL(price, f.Promotions, true, f.Prices)
+}
+
 type ProductsRepository struct {
 	db *gorm.DB
 }
@@ -16,44 +184,666 @@ func NewProductsRepository(db *gorm.DB) *ProductsRepository {
 
 func (r *ProductsRepository) GetAllProducts() ([]Product, error) {
 	var products []Product
-	if err := r.db.Preload("Category").Preload("Variants").Find(&products).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("Variants", withStock).Preload("Translations").Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func (r *ProductsRepository) GetProductsWithPagination(offset, limit int, category string, priceLessThan *float64) ([]Product, int64, error) {
+func (r *ProductsRepository) GetProductsWithPagination(offset, limit int, filter ProductFilter) ([]Product, int64, error) {
 	var products []Product
 	var total int64
 
-	query := r.db.Model(&Product{})
+	query, tsQuery := r.filterProducts(filter)
 
-	// category filter
-	if category != "" {
-		query = query.Joins("JOIN categories ON categories.id = products.category_id").
-			Where("categories.code = ?", category)
+	if !filter.SkipTotal {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
-	// price filter
-	if priceLessThan != nil {
-		query = query.Where("products.price < ?", *priceLessThan)
+	// With joins GORM would list every field, search_rank included, which only
+	// exists while searching
+	if tsQuery != "" {
+		query = query.Select("products.*, ts_rank(products.search_vector, to_tsquery('simple', ?)) AS search_rank", tsQuery)
+	} else {
+		query = query.Select("products.*")
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	salePrice := filter.productPriceSQL()
+
+	// keyset pagination, the total above still counts the whole result set
+	if len(filter.After) > 0 {
+		condition, values := keysetCondition(filter.Sort, filter.After, tsQuery, salePrice)
+		query = query.Where(condition, values...)
 	}
 
-	if err := query.Offset(offset).Limit(limit).Preload("Category").Preload("Variants").Find(&products).Error; err != nil {
+	query = orderProducts(query, filter.Sort, tsQuery != "", salePrice)
+
+	err := query.Offset(offset).Limit(limit).
+		Preload("Category.Translations").
+		Preload("Variants", withStock).
+		Preload("Translations").
+		Preload("Media", primaryMedia).
+		Find(&products).Error
+	if err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
 
+// GetCategoryFacets counts the products matching the filter per category,
+// products without a category are left out
+func (r *ProductsRepository) GetCategoryFacets(filter ProductFilter) ([]CategoryCount, error) {
+	matching, _ := r.filterProducts(filter)
+
+	var counts []CategoryCount
+	err := r.db.Table("categories").
+		Select("categories.code, categories.name, COUNT(*) AS count").
+		Joins("JOIN (?) AS matching ON matching.category_id = categories.id", matching.Select("products.category_id")).
+		Where("categories.deleted_at IS NULL").
+		Group("categories.code, categories.name").
+		Order("count DESC, categories.code").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// GetPriceFacets counts the products matching the filter in price buckets of
+// bucketSize width, only buckets with products are returned. Products are
+// bucketed by their sale price in the currency of the filter.
+func (r *ProductsRepository) GetPriceFacets(filter ProductFilter, bucketSize decimal.Decimal) ([]PriceBucket, error) {
+	matching, _ := r.filterProducts(filter)
+
+	var rows []struct {
+		Bucket int64
+		Count  int64
+	}
+	err := r.db.Table("(?) AS matching", matching.Select(filter.productPriceSQL()+" AS price")).
+		Select("FLOOR(matching.price / ?)::bigint AS bucket, COUNT(*) AS count", bucketSize).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]PriceBucket, len(rows))
+	for i, row := range rows {
+		lower := bucketSize.Mul(decimal.NewFromInt(row.Bucket))
+		buckets[i] = PriceBucket{
+			Min:   lower,
+			Max:   lower.Add(bucketSize),
+			Count: row.Count,
+		}
+	}
+	return buckets, nil
+}
+
+// GetProductByCode loads a product regardless of its status
 func (r *ProductsRepository) GetProductByCode(code string) (*Product, error) {
+	return r.getProductByCode(code)
+}
+
+// GetPublishedProductByCode only finds products of the public catalog
+func (r *ProductsRepository) GetPublishedProductByCode(code string) (*Product, error) {
+	return r.getProductByCode(code, published)
+}
+
+func (r *ProductsRepository) getProductByCode(code string, scopes ...func(*gorm.DB) *gorm.DB) (*Product, error) {
 	var product Product
-	if err := r.db.Where("code = ?", code).Preload("Category").Preload("Variants").First(&product).Error; err != nil {
-		return nil, err
+	err := r.db.Scopes(scopes...).Scopes(productDetails).Where("code = ?", code).First(&product).Error
+	if err != nil {
+		return nil, translateError(err)
 	}
 	return &product, nil
 }
+
+// GetPublishedProductsBySKUs loads the products of the public catalog with a
+// variant of one of the skus, as complete as GetPublishedProductByCode does
+func (r *ProductsRepository) GetPublishedProductsBySKUs(skus []string) ([]Product, error) {
+	var products []Product
+	if len(skus) == 0 {
+		return products, nil
+	}
+
+	err := r.db.Scopes(published, productDetails).
+		Where("EXISTS (SELECT 1 FROM "+liveVariants+" AND v.sku IN ?)", skus).
+		Order("products.id").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// productDetails preloads everything a product detail shows
+func productDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category.Translations").
+		Preload("Translations").
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", withStock).
+		Preload("Variants.Attributes.Option").
+		Preload("Variants.Attributes.OptionValue").
+		Preload("Media", byPositionAndID).
+		Preload("Media.Variant")
+}
+
+func (r *ProductsRepository) CreateProduct(product *Product, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		if err := lockLiveCategories(tx, product.CategoryID); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(product).Error
+	}))
+}
+
+func (r *ProductsRepository) UpdateProduct(product *Product, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		if err := lockLiveCategories(tx, product.CategoryID); err != nil {
+			return err
+		}
+		// Select forces nil/zero values (e.g. a cleared category) to be written too
+		result := tx.Model(product).Select("Price", "CategoryID", "Brand", "Status", "PublishFrom", "PublishUntil", "TaxClass").Updates(product)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+// ReplaceProductOptions makes options the option axes of a product, in the
+// given order. Options and values are matched by name, so variant attributes
+// keep pointing to them. Removing an option or value that a variant still
+// uses fails with ErrReferenced and changes nothing.
+func (r *ProductsRepository) ReplaceProductOptions(productID uint, options []ProductOption, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		optionIDs := []uint{0}
+
+		for i := range options {
+			option := &options[i]
+			option.ProductID = productID
+			option.Position = i
+
+			err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "product_id"}, {Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"position"}),
+			}).Create(option).Error
+			if err != nil {
+				return err
+			}
+			optionIDs = append(optionIDs, option.ID)
+
+			valueIDs := []uint{0}
+			for j := range option.Values {
+				value := &option.Values[j]
+				value.OptionID = option.ID
+				value.Position = j
+
+				err := tx.Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "option_id"}, {Name: "value"}},
+					DoUpdates: clause.AssignmentColumns([]string{"position"}),
+				}).Create(value).Error
+				if err != nil {
+					return err
+				}
+				valueIDs = append(valueIDs, value.ID)
+			}
+
+			err = tx.Where("option_id = ? AND id NOT IN ?", option.ID, valueIDs).Delete(&ProductOptionValue{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("product_id = ? AND id NOT IN ?", productID, optionIDs).Delete(&ProductOption{}).Error
+	}))
+}
+
+// SaveProductTranslation creates or replaces the texts of a product in a locale
+func (r *ProductsRepository) SaveProductTranslation(translation *ProductTranslation, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "description"}),
+		}).Create(translation).Error
+	}))
+}
+
+func (r *ProductsRepository) DeleteProductTranslation(productID uint, locale string, entry *AuditEntry) error {
+	return withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Where("product_id = ? AND locale = ?", productID, locale).Delete(&ProductTranslation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteProduct soft-deletes a product and its variants with the same
+// timestamp, so RestoreProduct can tell them from variants deleted before
+func (r *ProductsRepository) DeleteProduct(code string, entry *AuditEntry) error {
+	return withAudit(r.db, entry, func(tx *gorm.DB) error {
+		var product Product
+		if err := tx.Where("code = ?", code).First(&product).Error; err != nil {
+			return translateError(err)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&Variant{}).Where("product_id = ?", product.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&product).Update("deleted_at", now)
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// RestoreProduct brings back the most recently deleted product with the code
+// and the variants deleted with it. It fails with ErrDuplicateKey when the
+// code or one of the SKUs was taken again in the meantime and with
+// ErrCategoryDeleted while its category is deleted.
+func (r *ProductsRepository) RestoreProduct(code string, entry *AuditEntry) error {
+	return withAudit(r.db, entry, func(tx *gorm.DB) error {
+		var product Product
+		err := tx.Unscoped().
+			Where("code = ? AND deleted_at IS NOT NULL", code).
+			Order("deleted_at DESC").
+			First(&product).Error
+		if err != nil {
+			return translateError(err)
+		}
+		if err := lockLiveCategories(tx, product.CategoryID); err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&Variant{}).
+			Where("product_id = ? AND deleted_at = ?", product.ID, product.DeletedAt).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return translateError(err)
+		}
+
+		return translateError(tx.Unscoped().Model(&product).Update("deleted_at", nil).Error)
+	})
+}
+
+// GetProductsForImport loads the live products with one of the codes or
+// owning a variant with one of the skus, with their category and variants
+func (r *ProductsRepository) GetProductsForImport(codes, skus []string) ([]Product, error) {
+	var products []Product
+	if len(codes) == 0 && len(skus) == 0 {
+		return products, nil
+	}
+
+	err := r.db.Preload("Category").
+		Preload("Variants", withAttributes).
+		Where("code IN ? OR EXISTS (SELECT 1 FROM "+liveVariants+" AND v.sku IN ?)", codes, skus).
+		Order("products.id").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// ImportProducts upserts products by code and the variants listed on them by
+// sku in one transaction, together with the audit entries. Soft-deleted rows
+// don't conflict, the unique indexes only cover live ones, so a deleted code
+// or sku is created anew. Imported products keep their publishing window and
+// variants keep their attributes, a variant is never moved to another
+// product: that fails with ErrDuplicateKey.
+func (r *ProductsRepository) ImportProducts(products []Product, entries []*AuditEntry) error {
+	live := clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}}
+
+	return translateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if len(products) == 0 {
+			return nil
+		}
+
+		// Rows without ids, so new and existing products insert alike and the
+		// conflict on the code decides
+		rows := make([]Product, len(products))
+		categoryIDs := make([]*uint, len(products))
+		for i, p := range products {
+			categoryIDs[i] = p.CategoryID
+			rows[i] = Product{
+				Code:         p.Code,
+				Brand:        p.Brand,
+				Price:        p.Price,
+				Status:       p.Status,
+				PublishFrom:  p.PublishFrom,
+				PublishUntil: p.PublishUntil,
+				TaxClass:     p.TaxClass,
+				CategoryID:   p.CategoryID,
+			}
+		}
+
+		if err := lockLiveCategories(tx, categoryIDs...); err != nil {
+			return err
+		}
+
+		err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "code"}},
+			TargetWhere: live,
+			DoUpdates:   clause.AssignmentColumns([]string{"brand", "price", "status", "tax_class", "category_id"}),
+		}).Create(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		// A zero price is stored as NULL so the variant inherits the product price
+		var variants []map[string]any
+		for i := range products {
+			products[i].ID = rows[i].ID
+			for _, v := range products[i].Variants {
+				var price any
+				if !v.Price.IsZero() {
+					price = v.Price
+				}
+				variants = append(variants, map[string]any{
+					"product_id": rows[i].ID,
+					"sku":        v.SKU,
+					"name":       v.Name,
+					"price":      price,
+				})
+			}
+		}
+
+		if len(variants) > 0 {
+			result := tx.Model(&Variant{}).Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "sku"}},
+				TargetWhere: live,
+				DoUpdates:   clause.AssignmentColumns([]string{"name", "price"}),
+				Where: clause.Where{Exprs: []clause.Expression{
+					clause.Expr{SQL: "product_variants.product_id = excluded.product_id"},
+				}},
+			}).Create(variants)
+			if result.Error != nil {
+				return result.Error
+			}
+			// A sku that went to another product in the meantime isn't updated
+			if result.RowsAffected != int64(len(variants)) {
+				return ErrDuplicateKey
+			}
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(entries).Error
+	}))
+}
+
+// orderProducts applies the sort fields and adds products.id as the final
+// tie-breaker unless it's already part of the sort. Price sorts by salePrice.
+func orderProducts(query *gorm.DB, sort []SortField, searching bool, salePrice string) *gorm.DB {
+	hasID := false
+	for _, f := range sort {
+		column, ok := ProductSortColumns[f.Field]
+		if !ok || (f.Field == "relevance" && !searching) {
+			continue
+		}
+		if f.Field == "price" {
+			column = salePrice
+		}
+		hasID = hasID || f.Field == "id"
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}, Desc: f.Desc})
+	}
+
+	if !hasID {
+		query = query.Order("products.id")
+	}
+	return query
+}
+
+// filterProducts starts a products query with the conditions shared by the
+// listing and the facets. It returns the tsquery of the search, if any, so the
+// listing can rank by it.
+func (r *ProductsRepository) filterProducts(filter ProductFilter) (*gorm.DB, string) {
+	query := r.db.Model(&Product{})
+
+	// prices in the currency of the filter
+	if joins, values := filter.Prices.entryJoins("pp", false); joins != "" {
+		query = query.Joins(joins, values...)
+	}
+
+	// visibility
+	if !filter.IncludeUnpublished {
+		query = query.Scopes(published)
+	} else if filter.Status != "" {
+		query = query.Where("products.status = ?", filter.Status)
+	}
+
+	// category filter
+	if filter.Category != "" {
+		if filter.IncludeSubcategories {
+			query = query.Where("products.category_id IN (?)", r.db.Raw(categorySubtreeSQL, filter.Category))
+		} else {
+			query = query.Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+				Where("categories.code = ?", filter.Category)
+		}
+	}
+
+	// price filter
+	if condition, values := priceCondition(filter); condition != "" {
+		query = query.Where(condition, values...)
+	}
+
+	// stock filter
+	if filter.InStock {
+		query = query.Where("EXISTS (SELECT 1 FROM product_variants v JOIN stock_levels s ON s.variant_id = v.id " +
+			"WHERE v.product_id = products.id AND v.deleted_at IS NULL AND s.quantity > 0)")
+	}
+
+	// attribute filter
+	if condition, values := attributeCondition(filter.Attributes); condition != "" {
+		query = query.Where(condition, values...)
+	}
+
+	// full-text search
+	tsQuery := prefixTSQuery(filter.Search)
+	if tsQuery != "" {
+		query = query.Where("products.search_vector @@ to_tsquery('simple', ?)", tsQuery)
+	}
+
+	return query, tsQuery
+}
+
+// published restricts a query to the public catalog, active products whose
+// publishing window contains the current time of the database
+func published(db *gorm.DB) *gorm.DB {
+	return db.Where("products.status = ?", ProductStatusActive).
+		Where("(products.publish_from IS NULL OR products.publish_from <= NOW())").
+		Where("(products.publish_until IS NULL OR products.publish_until > NOW())")
+}
+
+// liveVariants selects the variants of the product in the outer query that
+// aren't soft-deleted, for use in EXISTS subqueries
+const liveVariants = "product_variants v WHERE v.product_id = products.id AND v.deleted_at IS NULL"
+
+// salePriceSQL wraps a price expression into the price the promotions sell it
+// for. It mirrors the evaluation of the catalog service: the first promotion
+// matching the product, or with variant also the variant "v", wins.
+// Percentages are rounded to cents, discounts never go below zero and a fixed
+// price never raises a price. Fixed values are converted into the currency of
+// view. The targets are matched against the promotion_targets view rather
+// than inlined, only promotion ids and decimals are, so the expression takes
+// no bind values and can be used in any clause.
+func salePriceSQL(price string, promotions []Promotion, variant bool, view PriceView) string {
+	var cases strings.Builder
+	for _, p := range promotions {
+		if len(p.TargetIDs) == 0 {
+			continue
+		}
+
+		var column string
+		switch p.Scope {
+		case PromotionScopeCategory:
+			column = "products.category_id"
+		case PromotionScopeProduct:
+			column = "products.id"
+		case PromotionScopeSKU:
+			if !variant {
+				continue
+			}
+			column = "v.id"
+		default:
+			continue
+		}
+
+		fixed := view.convertAmount(p.Value).String()
+		var discounted string
+		switch p.Type {
+		case PromotionPercentOff:
+			discounted = "ROUND(" + price + " * (100 - " + p.Value.String() + ") / 100, 2)"
+		case PromotionFixedOff:
+			discounted = "GREATEST(" + price + " - " + fixed + ", 0)"
+		case PromotionFixedPrice:
+			discounted = "LEAST(" + price + ", " + fixed + ")"
+		default:
+			continue
+		}
+
+		targets := "SELECT target_id FROM promotion_targets WHERE promotion_id = " + strconv.FormatUint(uint64(p.ID), 10)
+		cases.WriteString(" WHEN " + column + " IN (" + targets + ") THEN " + discounted)
+	}
+
+	if cases.Len() == 0 {
+		return price
+	}
+	return "(CASE" + cases.String() + " ELSE " + price + " END)"
+}
+
+// priceCondition combines the price bounds of the filter into one condition,
+// they apply to the sale prices in the currency of the filter
+func priceCondition(filter ProductFilter) (string, []any) {
+	var bounds []string
+	var values []any
+
+	add := func(operator string, price *decimal.Decimal) {
+		if price != nil {
+			bounds = append(bounds, operator+" ?")
+			values = append(values, *price)
+		}
+	}
+	add("<", filter.PriceLessThan)
+	add(">=", filter.PriceMin)
+	add("<=", filter.PriceMax)
+
+	if len(bounds) == 0 {
+		return "", nil
+	}
+
+	productPrice := filter.productPriceSQL()
+	productBounds := productPrice + " " + strings.Join(bounds, " AND "+productPrice+" ")
+	if !filter.MatchVariantPrices {
+		return productBounds, values
+	}
+
+	// The variants get their own price list entries
+	joins, joinValues := filter.Prices.entryJoins("vp", true)
+	variants := "product_variants v" + joins + " WHERE v.product_id = products.id AND v.deleted_at IS NULL"
+
+	variantPrice := filter.variantPriceSQL()
+	variantBounds := variantPrice + " " + strings.Join(bounds, " AND "+variantPrice+" ")
+
+	condition := "(EXISTS (SELECT 1 FROM " + variants + " AND " + variantBounds + ")" +
+		" OR (NOT EXISTS (SELECT 1 FROM " + liveVariants + ") AND " + productBounds + "))"
+
+	return condition, slices.Concat(joinValues, values, values)
+}
+
+// attributeCondition requires one variant to match all attribute filters, so
+// color=red and size=m finds a red M variant, not a red S and a blue M one
+func attributeCondition(attributes map[string][]string) (string, []any) {
+	if len(attributes) == 0 {
+		return "", nil
+	}
+
+	names := slices.Sorted(maps.Keys(attributes))
+	conditions := make([]string, len(names))
+	values := make([]any, 0, 2*len(names))
+
+	for i, name := range names {
+		conditions[i] = "EXISTS (SELECT 1 FROM variant_attributes va " +
+			"JOIN product_options o ON o.id = va.option_id " +
+			"JOIN product_option_values ov ON ov.id = va.option_value_id " +
+			"WHERE va.variant_id = v.id AND LOWER(o.name) = ? AND LOWER(ov.value) IN ?)"
+		values = append(values, name, attributes[name])
+	}
+
+	return "EXISTS (SELECT 1 FROM " + liveVariants + " AND " +
+		strings.Join(conditions, " AND ") + ")", values
+}
+
+// keysetCondition builds the WHERE clause for rows that sort after the given
+// values, e.g. for "-price,id" it's (price < ?) OR (price = ? AND id > ?).
+// The relevance column is an alias that WHERE can't see, so the rank
+// expression is repeated there. Price compares salePrice.
+func keysetCondition(sort []SortField, after []any, tsQuery, salePrice string) (string, []any) {
+	var ors []string
+	var values []any
+
+	column := func(i int) string {
+		if sort[i].Field == "relevance" {
+			values = append(values, tsQuery)
+			return "ts_rank(products.search_vector, to_tsquery('simple', ?))"
+		}
+		if sort[i].Field == "price" {
+			return salePrice
+		}
+		return ProductSortColumns[sort[i].Field]
+	}
+
+	for i := range min(len(sort), len(after)) {
+		var ands []string
+		for j := range i {
+			ands = append(ands, column(j)+" = ?")
+			values = append(values, after[j])
+		}
+
+		operator := " > ?"
+		if sort[i].Desc {
+			operator = " < ?"
+		}
+		ands = append(ands, column(i)+operator)
+		values = append(values, after[i])
+
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", values
+}
+
+// prefixTSQuery turns free text into a to_tsquery expression where every term
+// must match as a prefix (e.g. "prod00 red" becomes "prod00:* & red:*"), so
+// results show up while the user is still typing. Anything that isn't a
+// letter or digit separates terms, which also strips tsquery operators.
+func prefixTSQuery(search string) string {
+	terms := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
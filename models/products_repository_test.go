@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSalePriceSQL_MatchesTargetsInSQL(t *testing.T) {
+	subtree := make([]uint, 1000)
+	for i := range subtree {
+		subtree[i] = uint(i + 1000)
+	}
+	promotions := []Promotion{
+		{ID: 7, Type: PromotionPercentOff, Value: decimal.NewFromInt(20), Scope: PromotionScopeCategory, TargetIDs: subtree},
+		{ID: 8, Type: PromotionFixedOff, Value: decimal.NewFromInt(5), Scope: PromotionScopeProduct},
+	}
+
+	sql := salePriceSQL("products.price", promotions, false, PriceView{})
+
+	// The subtree ids stay in the database, a promotion without targets is
+	// left out
+	assert.Equal(t, "(CASE WHEN products.category_id IN (SELECT target_id FROM promotion_targets WHERE promotion_id = 7) "+
+		"THEN ROUND(products.price * (100 - 20) / 100, 2) ELSE products.price END)", sql)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Promotion discount types
+const (
+	PromotionPercentOff = "percent_off"
+	PromotionFixedOff   = "fixed_off"
+	PromotionFixedPrice = "fixed_price"
+)
+
+// Promotion scopes, the target is a category code, a product code or a
+// variant sku. A category promotion also covers the descendant categories.
+const (
+	PromotionScopeCategory = "category"
+	PromotionScopeProduct  = "product"
+	PromotionScopeSKU      = "sku"
+)
+
+// Promotion discounts the prices in its scope while StartsAt <= now < EndsAt,
+// a nil side leaves the window open. A price gets at most one promotion, the
+// matching one with the highest Priority, ties go to the oldest.
+type Promotion struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"not null"`
+	Type string `gorm:"not null"`
+	// Value is a percentage for percent_off and a base currency amount for
+	// the other types
+	Value    decimal.Decimal `gorm:"type:decimal(10,2);not null"`
+	Scope    string          `gorm:"not null"`
+	Target   string          `gorm:"not null"`
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Priority int `gorm:"not null;default:0"`
+
+	// TargetIDs are the category ids (the target and its descendants), the
+	// product id or the variant id the target resolves to. They are only
+	// filled by GetActivePromotions.
+	TargetIDs []uint `gorm:"-"`
+}
+
+func (p *Promotion) TableName() string {
+	return "promotions"
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type PromotionsRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionsRepository(db *gorm.DB) *PromotionsRepository {
+	return &PromotionsRepository{
+		db: db,
+	}
+}
+
+// GetPromotions returns promotions in the order they take precedence
+// together with their total count
+func (r *PromotionsRepository) GetPromotions(offset, limit int) ([]Promotion, int64, error) {
+	var total int64
+	if err := r.db.Model(&Promotion{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var promotions []Promotion
+	if err := r.db.Scopes(byPrecedence).Offset(offset).Limit(limit).Find(&promotions).Error; err != nil {
+		return nil, 0, err
+	}
+	return promotions, total, nil
+}
+
+func (r *PromotionsRepository) GetPromotion(id uint) (*Promotion, error) {
+	var promotion Promotion
+	if err := r.db.First(&promotion, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &promotion, nil
+}
+
+func (r *PromotionsRepository) CreatePromotion(promotion *Promotion) error {
+	return translateError(r.db.Create(promotion).Error)
+}
+
+func (r *PromotionsRepository) UpdatePromotion(promotion *Promotion) error {
+	// Select forces nil values (e.g. an opened window) to be written too
+	result := r.db.Model(promotion).
+		Select("Name", "Type", "Value", "Scope", "Target", "StartsAt", "EndsAt", "Priority").
+		Updates(promotion)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PromotionsRepository) DeletePromotion(id uint) error {
+	result := r.db.Delete(&Promotion{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// byPrecedence orders promotions the way they are applied, a price gets the
+// first one that matches it
+func byPrecedence(db *gorm.DB) *gorm.DB {
+	return db.Order("priority DESC, id")
+}
+
+// runningPromotions restricts a query to the promotions whose window contains
+// the current time of the database
+func runningPromotions(db *gorm.DB) *gorm.DB {
+	return db.Where("(starts_at IS NULL OR starts_at <= NOW())").
+		Where("(ends_at IS NULL OR ends_at > NOW())")
+}
+
+// resolvePromotionTargets fills the TargetIDs of the promotions from the
+// promotion_targets view in one query. Targets that don't exist (anymore)
+// resolve to no ids, the promotion then matches nothing.
+func resolvePromotionTargets(db *gorm.DB, promotions []Promotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(promotions))
+	for i, p := range promotions {
+		ids[i] = p.ID
+	}
+
+	var targets []struct {
+		PromotionID uint
+		TargetID    uint
+	}
+	err := db.Table("promotion_targets").
+		Where("promotion_id IN ?", ids).
+		Order("promotion_id, target_id").
+		Find(&targets).Error
+	if err != nil {
+		return err
+	}
+
+	index := make(map[uint]int, len(promotions))
+	for i, p := range promotions {
+		index[p.ID] = i
+	}
+	for _, t := range targets {
+		p := &promotions[index[t.PromotionID]]
+		p.TargetIDs = append(p.TargetIDs, t.TargetID)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeResult counts the rows removed by PurgeDeleted
+type PurgeResult struct {
+	Variants   int64
+	Products   int64
+	Categories int64
+}
+
+type PurgeRepository struct {
+	db *gorm.DB
+}
+
+func NewPurgeRepository(db *gorm.DB) *PurgeRepository {
+	return &PurgeRepository{
+		db: db,
+	}
+}
+
+// unreservedVariant and unreservedProduct keep the variants that reservation
+// items point at, and the products owning one, so a purge never takes lines
+// of an order with it. The foreign key restricts the delete on top of that.
+const (
+	unreservedVariant = "NOT EXISTS (SELECT 1 FROM reservation_items ri WHERE ri.variant_id = product_variants.id)"
+	unreservedProduct = "NOT EXISTS (SELECT 1 FROM product_variants v JOIN reservation_items ri ON ri.variant_id = v.id " +
+		"WHERE v.product_id = products.id)"
+)
+
+// PurgeDeleted permanently removes variants, products and categories that
+// were soft-deleted before the given time. Removing a product also removes
+// its remaining variants through ON DELETE CASCADE. Variants that were
+// reserved stay, together with their product. A category is only removed
+// after its children, so a parent waits for a child deleted later.
+func (r *PurgeRepository) PurgeDeleted(before time.Time) (PurgeResult, error) {
+	var result PurgeResult
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		variants := tx.Unscoped().Where("deleted_at < ?", before).Where(unreservedVariant).Delete(&Variant{})
+		if variants.Error != nil {
+			return variants.Error
+		}
+		result.Variants = variants.RowsAffected
+
+		products := tx.Unscoped().Where("deleted_at < ?", before).Where(unreservedProduct).Delete(&Product{})
+		if products.Error != nil {
+			return products.Error
+		}
+		result.Products = products.RowsAffected
+
+		// Leaves first, every round removes one level of the deleted subtrees
+		for {
+			categories := tx.Unscoped().
+				Where("deleted_at < ?", before).
+				Where("NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id = categories.id)").
+				Delete(&Category{})
+			if categories.Error != nil {
+				return categories.Error
+			}
+			if categories.RowsAffected == 0 {
+				return nil
+			}
+			result.Categories += categories.RowsAffected
+		}
+	})
+	if err != nil {
+		return PurgeResult{}, translateError(err)
+	}
+
+	return result, nil
+}
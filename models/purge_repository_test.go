@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingPool is a connection pool that records the statements instead of
+// running them, every statement affects no rows
+type recordingPool struct {
+	statements []string
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.statements = append(p.statements, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+func (p *recordingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &recordingTx{p}, nil
+}
+
+// recordingTx records the statements of a transaction into its pool
+type recordingTx struct {
+	*recordingPool
+}
+
+func (*recordingTx) Commit() error   { return nil }
+func (*recordingTx) Rollback() error { return nil }
+
+func TestPurgeDeleted_KeepsReservedVariants(t *testing.T) {
+	pool := &recordingPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = NewPurgeRepository(db).PurgeDeleted(time.Now())
+	require.NoError(t, err)
+
+	// Variants, products and one round of categories
+	require.Len(t, pool.statements, 3)
+
+	variants, products := pool.statements[0], pool.statements[1]
+	assert.True(t, strings.HasPrefix(variants, `DELETE FROM "product_variants"`), variants)
+	assert.Contains(t, variants, unreservedVariant)
+	assert.True(t, strings.HasPrefix(products, `DELETE FROM "products"`), products)
+	assert.Contains(t, products, unreservedProduct)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Reservation states. Only held reservations can change, confirming keeps
+// the stock taken, releasing and expiring give it back.
+const (
+	ReservationHeld      = "held"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation holds stock for a checkout until it's confirmed, released or
+// it expires
+type Reservation struct {
+	ID        uint              `gorm:"primaryKey"`
+	Status    string            `gorm:"not null"`
+	ExpiresAt time.Time         `gorm:"not null"`
+	CreatedAt time.Time         `gorm:"autoCreateTime"`
+	Items     []ReservationItem `gorm:"foreignKey:ReservationID"`
+}
+
+func (r *Reservation) TableName() string {
+	return "reservations"
+}
+
+// ReservationItem is the quantity of a variant held in one warehouse
+type ReservationItem struct {
+	ID            uint     `gorm:"primaryKey"`
+	ReservationID uint     `gorm:"not null"`
+	VariantID     uint     `gorm:"not null"`
+	Variant       *Variant `gorm:"foreignKey:VariantID"`
+	WarehouseID   uint     `gorm:"not null"`
+	Quantity      int      `gorm:"not null"`
+}
+
+func (r *ReservationItem) TableName() string {
+	return "reservation_items"
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationsRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationsRepository(db *gorm.DB) *ReservationsRepository {
+	return &ReservationsRepository{
+		db: db,
+	}
+}
+
+func (r *ReservationsRepository) GetReservation(id uint) (*Reservation, error) {
+	var reservation Reservation
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Variant").
+		First(&reservation, id).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &reservation, nil
+}
+
+// CreateReservation takes the stock of the reservation items and stores the
+// reservation as held. The items hold one variant each, they are split per
+// warehouse the stock is taken from. The stock rows are locked in variant and
+// warehouse order, so concurrent reservations can't deadlock or oversell.
+func (r *ReservationsRepository) CreateReservation(reservation *Reservation) error {
+	requested := slices.Clone(reservation.Items)
+	slices.SortFunc(requested, func(a, b ReservationItem) int {
+		return cmp.Compare(a.VariantID, b.VariantID)
+	})
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var items []ReservationItem
+
+		for _, item := range requested {
+			var levels []StockLevel
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("variant_id = ? AND quantity > 0", item.VariantID).
+				Order("warehouse_id").
+				Find(&levels).Error
+			if err != nil {
+				return err
+			}
+
+			missing := item.Quantity
+			for _, level := range levels {
+				if missing == 0 {
+					break
+				}
+
+				taken := min(level.Quantity, missing)
+				err := tx.Model(&StockLevel{}).
+					Where("variant_id = ? AND warehouse_id = ?", level.VariantID, level.WarehouseID).
+					Update("quantity", gorm.Expr("quantity - ?", taken)).Error
+				if err != nil {
+					return err
+				}
+
+				items = append(items, ReservationItem{
+					VariantID:   item.VariantID,
+					WarehouseID: level.WarehouseID,
+					Quantity:    taken,
+				})
+				missing -= taken
+			}
+
+			if missing > 0 {
+				return ErrInsufficientStock
+			}
+		}
+
+		reservation.Status = ReservationHeld
+		reservation.Items = items
+
+		if err := tx.Create(reservation).Error; err != nil {
+			return err
+		}
+
+		// Keep the variants of the request on the split items
+		for i := range reservation.Items {
+			for _, item := range requested {
+				if item.VariantID == reservation.Items[i].VariantID {
+					reservation.Items[i].Variant = item.Variant
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// ConfirmReservation turns a held, unexpired reservation into a confirmed
+// one. Its stock stays taken.
+func (r *ReservationsRepository) ConfirmReservation(id uint, now time.Time) error {
+	result := r.db.Model(&Reservation{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, ReservationHeld, now).
+		Update("status", ReservationConfirmed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.notHeld(r.db, id)
+	}
+	return nil
+}
+
+// ReleaseReservation gives the stock of a held reservation back
+func (r *ReservationsRepository) ReleaseReservation(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Reservation{}).
+			Where("id = ? AND status = ?", id, ReservationHeld).
+			Update("status", ReservationReleased)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return r.notHeld(tx, id)
+		}
+
+		return restoreStock(tx, []uint{id})
+	})
+}
+
+// ExpireReservations expires the held reservations that ran out before now,
+// gives their stock back and returns how many expired
+func (r *ReservationsRepository) ExpireReservations(now time.Time) (int, error) {
+	var ids []uint
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`UPDATE reservations SET status = ? WHERE status = ? AND expires_at <= ? RETURNING id`,
+			ReservationExpired, ReservationHeld, now).Scan(&ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return restoreStock(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// notHeld tells a missing reservation apart from one that can't change anymore
+func (r *ReservationsRepository) notHeld(db *gorm.DB, id uint) error {
+	var count int64
+	if err := db.Model(&Reservation{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrNotHeld
+}
+
+// restoreStock adds the items of the reservations back to the stock levels.
+// The items are summed first, UPDATE ... FROM applies one row per target.
+func restoreStock(tx *gorm.DB, reservationIDs []uint) error {
+	return tx.Exec(`UPDATE stock_levels s SET quantity = s.quantity + i.quantity, updated_at = NOW()
+		FROM (
+			SELECT variant_id, warehouse_id, SUM(quantity) AS quantity
+			FROM reservation_items
+			WHERE reservation_id IN ?
+			GROUP BY variant_id, warehouse_id
+		) i
+		WHERE s.variant_id = i.variant_id AND s.warehouse_id = i.warehouse_id`, reservationIDs).Error
+}
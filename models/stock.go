@@ -0,0 +1,26 @@
+package models
+
+// DefaultWarehouse receives the stock adjustments that don't name a warehouse
+const DefaultWarehouse = "DEFAULT"
+
+type Warehouse struct {
+	ID   uint   `gorm:"primaryKey"`
+	Code string `gorm:"uniqueIndex;not null"`
+	Name string `gorm:"not null"`
+}
+
+func (w *Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// StockLevel is the quantity of a variant held in one warehouse
+type StockLevel struct {
+	VariantID   uint       `gorm:"primaryKey"`
+	WarehouseID uint       `gorm:"primaryKey"`
+	Warehouse   *Warehouse `gorm:"foreignKey:WarehouseID"`
+	Quantity    int        `gorm:"not null"`
+}
+
+func (s *StockLevel) TableName() string {
+	return "stock_levels"
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// variantStockColumns selects the variant columns together with the stock
+// summed over all warehouses
+const variantStockColumns = "product_variants.*, " +
+	"(SELECT COALESCE(SUM(s.quantity), 0) FROM stock_levels s WHERE s.variant_id = product_variants.id) AS quantity"
+
+// withStock fills Variant.Quantity when loading variants
+func withStock(db *gorm.DB) *gorm.DB {
+	return db.Select(variantStockColumns)
+}
+
+type StockRepository struct {
+	db *gorm.DB
+}
+
+func NewStockRepository(db *gorm.DB) *StockRepository {
+	return &StockRepository{
+		db: db,
+	}
+}
+
+func (r *StockRepository) GetStockLevels(variantID uint) ([]StockLevel, error) {
+	var levels []StockLevel
+	err := r.db.Where("variant_id = ?", variantID).
+		Preload("Warehouse").
+		Order("warehouse_id").
+		Find(&levels).Error
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// AdjustStock adds delta to the stock of a variant in a warehouse and returns
+// the new quantity there. Each adjustment is a single statement that checks
+// and changes the row under its row lock, so concurrent decrements queue up
+// and re-check the quantity instead of overselling. The quantities before and
+// after are only known under that lock, so they're put into the audit entry
+// here.
+func (r *StockRepository) AdjustStock(variantID uint, warehouseCode string, delta int, entry *AuditEntry) (int, error) {
+	var warehouse Warehouse
+	if err := r.db.Where("code = ?", warehouseCode).First(&warehouse).Error; err != nil {
+		return 0, translateError(err)
+	}
+
+	var quantity int
+
+	err := withAudit(r.db, entry, func(tx *gorm.DB) error {
+		var result *gorm.DB
+		if delta >= 0 {
+			result = tx.Raw(`INSERT INTO stock_levels (variant_id, warehouse_id, quantity) VALUES (?, ?, ?)
+				ON CONFLICT (variant_id, warehouse_id) DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity, updated_at = NOW()
+				RETURNING quantity`, variantID, warehouse.ID, delta).Scan(&quantity)
+		} else {
+			result = tx.Raw(`UPDATE stock_levels SET quantity = quantity + ?, updated_at = NOW()
+				WHERE variant_id = ? AND warehouse_id = ? AND quantity + ? >= 0
+				RETURNING quantity`, delta, variantID, warehouse.ID, delta).Scan(&quantity)
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+
+		if entry != nil {
+			field := "stock." + warehouse.Code
+			entry.Before = map[string]any{field: quantity - delta}
+			entry.After = map[string]any{field: quantity}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return quantity, nil
+}
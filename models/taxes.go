@@ -0,0 +1,13 @@
+package models
+
+// Tax classes of products and categories. The rate of a class depends on the
+// country, a product without a class takes the one of its category.
+const (
+	TaxClassStandard = "standard"
+	TaxClassReduced  = "reduced"
+	TaxClassZero     = "zero"
+)
+
+// TaxClasses lists every tax class, each country configures a rate for all
+// of them
+var TaxClasses = []string{TaxClassStandard, TaxClassReduced, TaxClassZero}
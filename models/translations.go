@@ -0,0 +1,28 @@
+package models
+
+// DefaultLocale is the locale of the untranslated content, e.g. the name
+// stored on a category. It ends every locale fallback chain.
+const DefaultLocale = "en"
+
+// ProductTranslation holds the display texts of a product in one locale
+type ProductTranslation struct {
+	ProductID   uint   `gorm:"primaryKey"`
+	Locale      string `gorm:"primaryKey"`
+	Title       string `gorm:"not null"`
+	Description string `gorm:"not null"`
+}
+
+func (t *ProductTranslation) TableName() string {
+	return "product_translations"
+}
+
+// CategoryTranslation holds the name of a category in one locale
+type CategoryTranslation struct {
+	CategoryID uint   `gorm:"primaryKey"`
+	Locale     string `gorm:"primaryKey"`
+	Name       string `gorm:"not null"`
+}
+
+func (t *CategoryTranslation) TableName() string {
+	return "category_translations"
+}
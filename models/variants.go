@@ -2,14 +2,22 @@ package models
 
 import (
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Variant struct {
-	ID        uint            `gorm:"primaryKey"`
-	ProductID uint            `gorm:"not null"`
-	Name      string          `gorm:"not null"`
-	SKU       string          `gorm:"uniqueIndex;not null"`
-	Price     decimal.Decimal `gorm:"type:decimal(10,2);null"`
+	ID         uint               `gorm:"primaryKey"`
+	ProductID  uint               `gorm:"not null"`
+	Name       string             `gorm:"not null"`
+	SKU        string             `gorm:"uniqueIndex;not null"`
+	Price      decimal.Decimal    `gorm:"type:decimal(10,2);null"`
+	Attributes []VariantAttribute `gorm:"foreignKey:VariantID"`
+	// DeletedAt soft-deletes the variant, it keeps its attributes and stock
+	// so a restore brings it back as it was
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// Quantity is the stock summed over all warehouses. It's only filled by
+	// queries that select it and never written back.
+	Quantity int `gorm:"->;column:quantity"`
 }
 
 func (v *Variant) TableName() string {
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VariantsRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantsRepository(db *gorm.DB) *VariantsRepository {
+	return &VariantsRepository{
+		db: db,
+	}
+}
+
+func (r *VariantsRepository) GetVariantsByProductID(productID uint) ([]Variant, error) {
+	var variants []Variant
+	if err := r.db.Scopes(withStock, withAttributes).Where("product_id = ?", productID).Order("id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *VariantsRepository) GetVariantBySKU(sku string) (*Variant, error) {
+	var variant Variant
+	if err := r.db.Scopes(withStock, withAttributes).Where("sku = ?", sku).First(&variant).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &variant, nil
+}
+
+// GetPublishedVariantBySKU finds a variant of a product in the public
+// catalog, see published
+func (r *VariantsRepository) GetPublishedVariantBySKU(sku string) (*Variant, error) {
+	var variant Variant
+	err := r.db.Scopes(withStock, withAttributes, published).
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL").
+		Where("product_variants.sku = ?", sku).
+		First(&variant).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &variant, nil
+}
+
+func (r *VariantsRepository) CreateVariant(variant *Variant, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		omit := []string{clause.Associations}
+		// A zero price is stored as NULL so the variant inherits the product price
+		if variant.Price.IsZero() {
+			omit = append(omit, "Price")
+		}
+		if err := tx.Omit(omit...).Create(variant).Error; err != nil {
+			return err
+		}
+
+		return replaceAttributes(tx, variant)
+	}))
+}
+
+func (r *VariantsRepository) UpdateVariant(variant *Variant, entry *AuditEntry) error {
+	var price any
+	if !variant.Price.IsZero() {
+		price = variant.Price
+	}
+
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Model(variant).Updates(map[string]any{
+			"name":  variant.Name,
+			"sku":   variant.SKU,
+			"price": price,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return replaceAttributes(tx, variant)
+	}))
+}
+
+func (r *VariantsRepository) DeleteVariant(id uint, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		result := tx.Delete(&Variant{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+// RestoreVariant brings back the most recently deleted variant of a product
+// with the SKU. It fails with ErrDuplicateKey when the SKU was taken again.
+func (r *VariantsRepository) RestoreVariant(productID uint, sku string, entry *AuditEntry) error {
+	return translateError(withAudit(r.db, entry, func(tx *gorm.DB) error {
+		var variant Variant
+		err := tx.Unscoped().
+			Where("product_id = ? AND sku = ? AND deleted_at IS NOT NULL", productID, sku).
+			Order("deleted_at DESC").
+			First(&variant).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&variant).Update("deleted_at", nil).Error
+	}))
+}
+
+// withAttributes preloads the option and value of every variant attribute
+func withAttributes(db *gorm.DB) *gorm.DB {
+	return db.Preload("Attributes.Option").Preload("Attributes.OptionValue")
+}
+
+// replaceAttributes stores the attributes of a variant in place of its
+// current ones
+func replaceAttributes(tx *gorm.DB, variant *Variant) error {
+	if err := tx.Where("variant_id = ?", variant.ID).Delete(&VariantAttribute{}).Error; err != nil {
+		return err
+	}
+
+	if len(variant.Attributes) == 0 {
+		return nil
+	}
+
+	for i := range variant.Attributes {
+		variant.Attributes[i].VariantID = variant.ID
+	}
+	return tx.Omit(clause.Associations).Create(&variant.Attributes).Error
+}
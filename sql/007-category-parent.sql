@@ -0,0 +1,5 @@
+-- RESTRICT keeps a parent from being deleted while it still has children
+ALTER TABLE categories
+ADD COLUMN parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
@@ -0,0 +1,45 @@
+-- Full-text search over products. The vector combines the product code with
+-- the SKUs and names of its variants, so it's kept up to date by triggers on
+-- both tables instead of a generated column.
+ALTER TABLE products
+ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION product_search_vector(p_id INTEGER) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(p.code, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce(string_agg(v.sku, ' '), '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce(string_agg(v.name, ' '), '')), 'B')
+    FROM products p
+    LEFT JOIN product_variants v ON v.product_id = p.id
+    WHERE p.id = p_id
+    GROUP BY p.id
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION refresh_product_search_vector() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'products' THEN
+        UPDATE products SET search_vector = product_search_vector(NEW.id) WHERE id = NEW.id;
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE products SET search_vector = product_search_vector(OLD.product_id) WHERE id = OLD.product_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE products SET search_vector = product_search_vector(NEW.product_id) WHERE id = NEW.product_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Only fires on code changes, so the UPDATE inside the trigger doesn't recurse
+CREATE TRIGGER products_search_vector
+AFTER INSERT OR UPDATE OF code ON products
+FOR EACH ROW EXECUTE FUNCTION refresh_product_search_vector();
+
+CREATE TRIGGER product_variants_search_vector
+AFTER INSERT OR UPDATE OR DELETE ON product_variants
+FOR EACH ROW EXECUTE FUNCTION refresh_product_search_vector();
+
+UPDATE products SET search_vector = product_search_vector(id);
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
@@ -0,0 +1,32 @@
+-- Markets sell in a single currency
+CREATE TABLE IF NOT EXISTS markets (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Rates convert from the base currency (EUR) when no price list entry exists
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY,
+    rate DECIMAL(18, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Explicit prices per product (variant_id NULL) or variant, currency and
+-- optionally market. A NULL market applies to all markets of the currency.
+CREATE TABLE IF NOT EXISTS price_list_entries (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    market VARCHAR(32) REFERENCES markets(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_list_entries_unique
+ON price_list_entries (product_id, COALESCE(variant_id, 0), currency, COALESCE(market, ''));
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) UNIQUE NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- One row per variant and warehouse. The CHECK is the last line of defence,
+-- adjustments already refuse to go below zero.
+CREATE TABLE IF NOT EXISTS stock_levels (
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (variant_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_in_stock ON stock_levels(variant_id) WHERE quantity > 0;
+
+-- Stock adjustments that don't name a warehouse go to DEFAULT
+INSERT INTO warehouses (code, name) VALUES
+('DEFAULT', 'Default warehouse')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,33 @@
+-- Option axes of a product (e.g. Size, Color) and their allowed values
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_option_values (
+    id SERIAL PRIMARY KEY,
+    option_id INTEGER NOT NULL REFERENCES product_options(id) ON DELETE CASCADE,
+    value VARCHAR(64) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (option_id, value)
+);
+
+-- One value per variant and option. Options and values in use can't be
+-- removed, deleting the variant or the product removes its attributes.
+CREATE TABLE IF NOT EXISTS variant_attributes (
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    option_id INTEGER NOT NULL REFERENCES product_options(id) ON DELETE RESTRICT,
+    option_value_id INTEGER NOT NULL REFERENCES product_option_values(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (variant_id, option_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_variant_attributes_option_value_id ON variant_attributes(option_value_id);
@@ -0,0 +1,46 @@
+-- Brands are shown as is, titles and descriptions are translated per locale.
+-- Locales are BCP 47 tags like "en", "de" or "de-CH".
+ALTER TABLE products
+ADD COLUMN brand VARCHAR(128) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    locale VARCHAR(16) NOT NULL,
+    title VARCHAR(256) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (product_id, locale)
+);
+
+-- categories.name stays the name in the default locale (en)
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    locale VARCHAR(16) NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (category_id, locale)
+);
+
+-- Search also covers brands, titles and descriptions of every locale.
+-- Subqueries keep variants and translations from multiplying each other.
+CREATE OR REPLACE FUNCTION product_search_vector(p_id INTEGER) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', p.code || ' ' || p.brand), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(v.sku, ' ') FROM product_variants v WHERE v.product_id = p.id), '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(t.title, ' ') FROM product_translations t WHERE t.product_id = p.id), '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(v.name, ' ') FROM product_variants v WHERE v.product_id = p.id), '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(t.description, ' ') FROM product_translations t WHERE t.product_id = p.id), '')), 'C')
+    FROM products p
+    WHERE p.id = p_id
+$$ LANGUAGE sql STABLE;
+
+DROP TRIGGER IF EXISTS products_search_vector ON products;
+
+CREATE TRIGGER products_search_vector
+AFTER INSERT OR UPDATE OF code, brand ON products
+FOR EACH ROW EXECUTE FUNCTION refresh_product_search_vector();
+
+CREATE TRIGGER product_translations_search_vector
+AFTER INSERT OR UPDATE OR DELETE ON product_translations
+FOR EACH ROW EXECUTE FUNCTION refresh_product_search_vector();
@@ -0,0 +1,17 @@
+-- References to product images in the external media store. Deleting a
+-- variant keeps its images in the product gallery.
+CREATE TABLE IF NOT EXISTS product_media (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    url VARCHAR(2048) NOT NULL,
+    alt_text VARCHAR(256) NOT NULL DEFAULT '',
+    width INTEGER NOT NULL DEFAULT 0 CHECK (width >= 0),
+    height INTEGER NOT NULL DEFAULT 0 CHECK (height >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_media_product_position ON product_media(product_id, position, id);
+CREATE INDEX IF NOT EXISTS idx_product_media_variant_id ON product_media(variant_id);
@@ -0,0 +1,14 @@
+-- Lifecycle status and publishing window of products. New products start as
+-- drafts, the products that existed before were all public.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'draft';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_from TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_until TIMESTAMPTZ;
+
+ALTER TABLE products ADD CONSTRAINT products_status_check
+    CHECK (status IN ('draft', 'active', 'archived'));
+ALTER TABLE products ADD CONSTRAINT products_publish_window_check
+    CHECK (publish_from IS NULL OR publish_until IS NULL OR publish_until > publish_from);
+
+UPDATE products SET status = 'active';
+
+CREATE INDEX IF NOT EXISTS idx_products_status ON products(status, publish_from, publish_until);
@@ -0,0 +1,37 @@
+-- Soft deletes for products, variants and categories. Deleted rows keep their
+-- code or SKU, so the unique constraints only cover the rows still alive and
+-- a new row may reuse the code of a deleted one.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_product_variants_deleted_at ON product_variants(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_code_key ON products(code) WHERE deleted_at IS NULL;
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_sku_key ON product_variants(sku) WHERE deleted_at IS NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_code_key ON categories(code) WHERE deleted_at IS NULL;
+
+-- Purging deleted rows must not take reservation lines with it, the purge
+-- skips reserved variants and this makes sure of it
+ALTER TABLE reservation_items DROP CONSTRAINT IF EXISTS reservation_items_variant_id_fkey;
+ALTER TABLE reservation_items ADD CONSTRAINT reservation_items_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE RESTRICT;
+
+-- Deleted variants no longer make their product searchable. The variants
+-- trigger fires on every update, so it also covers deleting and restoring.
+CREATE OR REPLACE FUNCTION product_search_vector(p_id INTEGER) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', p.code || ' ' || p.brand), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(v.sku, ' ') FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL), '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(t.title, ' ') FROM product_translations t WHERE t.product_id = p.id), '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(v.name, ' ') FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL), '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce((SELECT string_agg(t.description, ' ') FROM product_translations t WHERE t.product_id = p.id), '')), 'C')
+    FROM products p
+    WHERE p.id = p_id
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,27 @@
+-- Every write to products, variants and categories leaves an entry here. The
+-- code is the product code, variant sku or category code and isn't a foreign
+-- key, entries outlive the rows they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(128) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    entity VARCHAR(16) NOT NULL CHECK (entity IN ('product', 'variant', 'category')),
+    code VARCHAR(32) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity_code ON audit_log(entity, code, id);
+
+-- The log is append-only, entries can't be changed or removed afterwards
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
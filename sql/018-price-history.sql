@@ -0,0 +1,76 @@
+-- Base price of every product and variant over time. A row is in effect during
+-- [valid_from, valid_to), the open row is the current price. Variant rows with
+-- a NULL amount are periods the variant inherited the product price. Rows are
+-- written by triggers, so every price change is captured whichever code path
+-- makes it. Like the audit log, the history outlives the rows: it keeps the
+-- product code and the sku, and purging a product or variant only clears the
+-- reference to it. Product rows are the ones without a sku.
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+    variant_id INTEGER REFERENCES product_variants(id) ON DELETE SET NULL,
+    product_code VARCHAR(32) NOT NULL,
+    sku VARCHAR(32),
+    amount DECIMAL(10, 2),
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ,
+    -- Changing a price twice in one transaction leaves an empty period
+    CHECK (valid_to IS NULL OR valid_to >= valid_from),
+    CHECK (sku IS NOT NULL OR amount IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product ON price_history(product_id, valid_from);
+
+-- At most one current price per product and per variant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_history_current
+ON price_history(product_id, COALESCE(variant_id, 0)) WHERE valid_to IS NULL;
+
+CREATE OR REPLACE FUNCTION record_price_history() RETURNS trigger AS $$
+DECLARE
+    p_id INTEGER;
+    v_id INTEGER;
+    p_code VARCHAR;
+    v_sku VARCHAR;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.price IS NOT DISTINCT FROM OLD.price THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_TABLE_NAME = 'products' THEN
+        p_id := NEW.id;
+        p_code := NEW.code;
+    ELSE
+        p_id := NEW.product_id;
+        v_id := NEW.id;
+        v_sku := NEW.sku;
+        SELECT code INTO p_code FROM products WHERE id = p_id;
+    END IF;
+
+    UPDATE price_history SET valid_to = NOW()
+    WHERE product_id = p_id AND COALESCE(variant_id, 0) = COALESCE(v_id, 0) AND valid_to IS NULL;
+
+    INSERT INTO price_history (product_id, variant_id, product_code, sku, amount, valid_from)
+    VALUES (p_id, v_id, p_code, v_sku, NEW.price, NOW());
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_price_history ON products;
+CREATE TRIGGER products_price_history
+AFTER INSERT OR UPDATE OF price ON products
+FOR EACH ROW EXECUTE FUNCTION record_price_history();
+
+DROP TRIGGER IF EXISTS product_variants_price_history ON product_variants;
+CREATE TRIGGER product_variants_price_history
+AFTER INSERT OR UPDATE OF price ON product_variants
+FOR EACH ROW EXECUTE FUNCTION record_price_history();
+
+-- The prices stored so far have been in effect since their row was created
+INSERT INTO price_history (product_id, product_code, amount, valid_from)
+SELECT id, code, price, COALESCE(created_at, NOW()) FROM products
+WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.product_id = products.id AND h.sku IS NULL);
+
+INSERT INTO price_history (product_id, variant_id, product_code, sku, amount, valid_from)
+SELECT v.product_id, v.id, p.code, v.sku, v.price, COALESCE(v.created_at, NOW())
+FROM product_variants v JOIN products p ON p.id = v.product_id
+WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.variant_id = v.id);
@@ -0,0 +1,47 @@
+-- Promotions discount the prices of a category (and its descendants), a
+-- product or a variant while their window is running. The target is a code or
+-- sku, not a foreign key, so a promotion outlives its target and matches
+-- nothing once the target is deleted.
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('percent_off', 'fixed_off', 'fixed_price')),
+    -- A percentage for percent_off, a base currency amount otherwise
+    value DECIMAL(10, 2) NOT NULL CHECK (value >= 0),
+    scope VARCHAR(16) NOT NULL CHECK (scope IN ('category', 'product', 'sku')),
+    target VARCHAR(32) NOT NULL,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (type <> 'percent_off' OR value <= 100),
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_precedence ON promotions(priority DESC, id);
+
+-- The ids the targets of the running promotions resolve to: the category and
+-- its descendants, the product or the variant. Deleted rows are left out, so
+-- a promotion whose target is gone has no rows. Listings match prices against
+-- it in SQL instead of carrying the ids along.
+CREATE OR REPLACE VIEW promotion_targets AS
+WITH RECURSIVE running AS (
+    SELECT id, scope, target FROM promotions
+    WHERE (starts_at IS NULL OR starts_at <= NOW()) AND (ends_at IS NULL OR ends_at > NOW())
+), subtree AS (
+    SELECT r.id AS promotion_id, c.id AS target_id
+    FROM running r JOIN categories c ON c.code = r.target AND c.deleted_at IS NULL
+    WHERE r.scope = 'category'
+    UNION
+    SELECT s.promotion_id, c.id
+    FROM categories c JOIN subtree s ON c.parent_id = s.target_id
+    WHERE c.deleted_at IS NULL
+)
+SELECT promotion_id, target_id FROM subtree
+UNION ALL
+SELECT r.id, p.id FROM running r JOIN products p ON p.code = r.target AND p.deleted_at IS NULL
+WHERE r.scope = 'product'
+UNION ALL
+SELECT r.id, v.id FROM running r JOIN product_variants v ON v.sku = r.target AND v.deleted_at IS NULL
+WHERE r.scope = 'sku';
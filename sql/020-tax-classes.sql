@@ -0,0 +1,9 @@
+-- Tax classes of products and categories. NULL inherits: a product takes the
+-- class of its category, a category the one of its parent, and the top level
+-- falls back to standard. The rates per country are configured locally, see
+-- TAX_RATES_FILE.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class VARCHAR(16)
+    CHECK (tax_class IN ('standard', 'reduced', 'zero'));
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_class VARCHAR(16)
+    CHECK (tax_class IN ('standard', 'reduced', 'zero'));
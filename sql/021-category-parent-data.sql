@@ -0,0 +1,6 @@
+-- Insert subcategories
+INSERT INTO categories (code, name, parent_id) VALUES
+('DRESSES', 'Dresses', (SELECT id FROM categories WHERE code = 'CLOTHING'));
+
+INSERT INTO categories (code, name, parent_id) VALUES
+('MAXI_DRESSES', 'Maxi Dresses', (SELECT id FROM categories WHERE code = 'DRESSES'));
@@ -0,0 +1,19 @@
+-- Exchange rates, markets and list prices
+INSERT INTO exchange_rates (currency, rate) VALUES
+('EUR', 1),
+('GBP', 0.85),
+('USD', 1.08),
+('CHF', 0.95);
+
+INSERT INTO markets (code, currency) VALUES
+('DE', 'EUR'),
+('FR', 'EUR'),
+('UK', 'GBP'),
+('US', 'USD'),
+('CH', 'CHF');
+
+-- PROD001 has list prices in GBP, a US specific price and a CHF variant price
+INSERT INTO price_list_entries (product_id, variant_id, currency, market, amount) VALUES
+((SELECT id FROM products WHERE code = 'PROD001'), NULL, 'GBP', NULL, 9.49),
+((SELECT id FROM products WHERE code = 'PROD001'), NULL, 'USD', 'US', 11.99),
+((SELECT id FROM products WHERE code = 'PROD001'), (SELECT id FROM product_variants WHERE sku = 'SKU001A'), 'CHF', NULL, 12.90);
@@ -0,0 +1,8 @@
+INSERT INTO warehouses (code, name) VALUES
+('MUNICH', 'Munich distribution center');
+
+INSERT INTO stock_levels (variant_id, warehouse_id, quantity) VALUES
+((SELECT id FROM product_variants WHERE sku = 'SKU001A'), (SELECT id FROM warehouses WHERE code = 'DEFAULT'), 5),
+((SELECT id FROM product_variants WHERE sku = 'SKU001A'), (SELECT id FROM warehouses WHERE code = 'MUNICH'), 2),
+((SELECT id FROM product_variants WHERE sku = 'SKU001B'), (SELECT id FROM warehouses WHERE code = 'DEFAULT'), 0),
+((SELECT id FROM product_variants WHERE sku = 'SKU002A'), (SELECT id FROM warehouses WHERE code = 'DEFAULT'), 12);
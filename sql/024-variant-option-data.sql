@@ -0,0 +1,38 @@
+-- PROD001 comes in three colors, PROD004 in two colors and two sizes
+INSERT INTO product_options (product_id, name, position) VALUES
+((SELECT id FROM products WHERE code = 'PROD001'), 'Color', 0),
+((SELECT id FROM products WHERE code = 'PROD004'), 'Color', 0),
+((SELECT id FROM products WHERE code = 'PROD004'), 'Size', 1);
+
+INSERT INTO product_option_values (option_id, value, position)
+SELECT o.id, v.value, v.position
+FROM product_options o
+JOIN products p ON p.id = o.product_id
+JOIN (VALUES
+    ('PROD001', 'Color', 'Red', 0),
+    ('PROD001', 'Color', 'Blue', 1),
+    ('PROD001', 'Color', 'Green', 2),
+    ('PROD004', 'Color', 'Red', 0),
+    ('PROD004', 'Color', 'Black', 1),
+    ('PROD004', 'Size', 'S', 0),
+    ('PROD004', 'Size', 'M', 1)
+) AS v(code, option, value, position) ON v.code = p.code AND v.option = o.name;
+
+INSERT INTO variant_attributes (variant_id, option_id, option_value_id)
+SELECT pv.id, o.id, ov.id
+FROM (VALUES
+    ('SKU001A', 'Color', 'Red'),
+    ('SKU001B', 'Color', 'Blue'),
+    ('SKU001C', 'Color', 'Green'),
+    ('SKU004A', 'Color', 'Red'),
+    ('SKU004A', 'Size', 'S'),
+    ('SKU004B', 'Color', 'Red'),
+    ('SKU004B', 'Size', 'M'),
+    ('SKU004C', 'Color', 'Black'),
+    ('SKU004C', 'Size', 'S'),
+    ('SKU004D', 'Color', 'Black'),
+    ('SKU004D', 'Size', 'M')
+) AS a(sku, option, value)
+JOIN product_variants pv ON pv.sku = a.sku
+JOIN product_options o ON o.product_id = pv.product_id AND o.name = a.option
+JOIN product_option_values ov ON ov.option_id = o.id AND ov.value = a.value;
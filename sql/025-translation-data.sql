@@ -0,0 +1,15 @@
+-- Brands and translated titles, descriptions and category names
+UPDATE products SET brand = 'Acme' WHERE code IN ('PROD001', 'PROD002', 'PROD003');
+UPDATE products SET brand = 'Northwind' WHERE code IN ('PROD004', 'PROD005');
+
+INSERT INTO product_translations (product_id, locale, title, description) VALUES
+((SELECT id FROM products WHERE code = 'PROD001'), 'en', 'Cotton T-Shirt', 'A soft cotton t-shirt for every day.'),
+((SELECT id FROM products WHERE code = 'PROD001'), 'de', 'Baumwoll-T-Shirt', 'Ein weiches Baumwoll-T-Shirt für jeden Tag.'),
+((SELECT id FROM products WHERE code = 'PROD004'), 'en', 'Linen Shirt', 'A relaxed shirt in breathable linen.'),
+((SELECT id FROM products WHERE code = 'PROD004'), 'fr', 'Chemise en lin', 'Une chemise décontractée en lin respirant.');
+
+INSERT INTO category_translations (category_id, locale, name) VALUES
+((SELECT id FROM categories WHERE code = 'CLOTHING'), 'de', 'Kleidung'),
+((SELECT id FROM categories WHERE code = 'CLOTHING'), 'fr', 'Vêtements'),
+((SELECT id FROM categories WHERE code = 'SHOES'), 'de', 'Schuhe'),
+((SELECT id FROM categories WHERE code = 'SHOES'), 'fr', 'Chaussures');
@@ -0,0 +1,11 @@
+-- Product images, some of them shown for a variant
+INSERT INTO product_media (product_id, variant_id, url, alt_text, width, height, position)
+SELECT p.id, pv.id, m.url, m.alt_text, 1200, 1600, m.position
+FROM (VALUES
+    ('PROD001', NULL, 'https://media.example.com/prod001/front.jpg', 'Front view', 0),
+    ('PROD001', 'SKU001A', 'https://media.example.com/prod001/red.jpg', 'Red', 1),
+    ('PROD001', 'SKU001B', 'https://media.example.com/prod001/blue.jpg', 'Blue', 2),
+    ('PROD004', NULL, 'https://media.example.com/prod004/front.jpg', 'Front view', 0)
+) AS m(code, sku, url, alt_text, position)
+JOIN products p ON p.code = m.code
+LEFT JOIN product_variants pv ON pv.sku = m.sku;